@@ -1,8 +1,30 @@
 package connection
 
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
 type Base struct {
 	DriverName string
 	Delimiter  string
+
+	// initErr is the error occurred in the InitDB.
+	initErr error
+	// options are the options of the connections and replicas keyed by the
+	// names of them.
+	options map[string]*dbOptions
+	// replicaGroups are the replicas of the connections which have them.
+	replicaGroups map[string]*replicaGroup
+
+	healthMu sync.Mutex
+	// health is the status of the last health check.
+	health map[string]HealthStatus
+	// stopHealth stops the background health check, which is nil if it is
+	// not running, and healthDone is closed once it returns.
+	stopHealth chan struct{}
+	healthDone chan struct{}
 }
 
 // GetDelimiter implements the method Connection.GetDelimiter.
@@ -14,3 +36,86 @@ func (base *Base) GetDelimiter() string {
 func (base *Base) Name() string {
 	return base.DriverName
 }
+
+// StmtCache implements the method StmtCacher.StmtCache.
+func (base *Base) StmtCache(con string) *StmtCache {
+	return base.connOptions(con).cache()
+}
+
+// initOptions sets the options of the connection given by cfg, which are
+// passed to the performers by the methods of the driver.
+func (base *Base) initOptions(con string, db *sql.DB, cfg Database) {
+	opts := &dbOptions{}
+	if len(cfg.Converters) > 0 {
+		// the keys are normalized as RegisterConverter does
+		opts.converters = make(Converters, len(cfg.Converters))
+		for typ, converter := range cfg.Converters {
+			opts.converters[DatabaseType(normalizeTypeName(string(typ)))] = converter
+		}
+	}
+	if cfg.StmtCacheSize > 0 {
+		opts.stmtCache = newStmtCache(db, cfg.StmtCacheSize)
+	}
+	if base.options == nil {
+		base.options = make(map[string]*dbOptions)
+	}
+	base.options[con] = opts
+}
+
+// connOptions return the options of the connection con, or nil if there is
+// none.
+func (base *Base) connOptions(con string) *dbOptions {
+	return base.options[con]
+}
+
+// txConnKey is the context key of the connection name of the transaction.
+type txConnKey struct{}
+
+// TxContext return the context of the methods within the transaction started
+// on the connection con, by which the options of con, e.g. the statement cache
+// and the converters, apply to them. The SQL builder sets it by the connection
+// of SQL.
+//
+//	tx, _ := conn.BeginTxAndConnection("read")
+//	conn.QueryWithTxContext(connection.TxContext(ctx, "read"), tx, "select * from users")
+func TxContext(ctx context.Context, con string) context.Context {
+	return context.WithValue(ctx, txConnKey{}, con)
+}
+
+// txOptions return the options of the connection which the transaction is
+// started on. If the context does not carry it, the default connection is
+// assumed as the other methods without the connection name, but the cached
+// statements of it are not rebound, which may not be of the database of the
+// transaction.
+func (base *Base) txOptions(ctx context.Context) *dbOptions {
+	if con, ok := ctx.Value(txConnKey{}).(string); ok {
+		return base.connOptions(con)
+	}
+	opts := base.connOptions("default")
+	if opts.cache() == nil {
+		return opts
+	}
+	return &dbOptions{converters: opts.converters}
+}
+
+// dbOptions are the options of a connection opened by the drivers.
+type dbOptions struct {
+	stmtCache  *StmtCache
+	converters Converters
+}
+
+// cache return the statement cache, or nil if it is disabled.
+func (opts *dbOptions) cache() *StmtCache {
+	if opts == nil {
+		return nil
+	}
+	return opts.stmtCache
+}
+
+// convs return the converters of the connection.
+func (opts *dbOptions) convs() Converters {
+	if opts == nil {
+		return nil
+	}
+	return opts.converters
+}
@@ -1,7 +1,12 @@
 package connection
 
 import (
+	"context"
 	"database/sql"
+	"github.com/chenhg5/go-sql/dialect"
+	"sort"
+	"sync"
+	"time"
 )
 
 const (
@@ -17,8 +22,9 @@ const (
 
 // Connection is a connection handler of database.
 type Connection interface {
-	// InitDB initialize the database connections.
-	InitDB(cfg map[string]Database) Connection
+	// InitDB initialize the database connections. The error of opening is
+	// returned on every call once the initialization failed.
+	InitDB(cfg map[string]Database) (Connection, error)
 
 	// Name get the connection`s name.
 	Name() string
@@ -38,6 +44,37 @@ type Connection interface {
 	// ExecWithConnection is the exec method with given connection of sql.
 	ExecWithConnection(conn, query string, args ...interface{}) (sql.Result, error)
 
+	// QueryContext is the query method of sql with the given context.
+	QueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error)
+
+	// ExecContext is the exec method of sql with the given context.
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+
+	// QueryWithConnectionContext is the query method with given context and connection of sql.
+	QueryWithConnectionContext(ctx context.Context, conn, query string, args ...interface{}) ([]map[string]interface{}, error)
+
+	// ExecWithConnectionContext is the exec method with given context and connection of sql.
+	ExecWithConnectionContext(ctx context.Context, conn, query string, args ...interface{}) (sql.Result, error)
+
+	// QueryRows is the query method of sql which returns a cursor of the results.
+	QueryRows(query string, args ...interface{}) (*Rows, error)
+
+	// QueryRowsContext is the query method of sql with the given context which returns
+	// a cursor of the results.
+	QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+
+	// QueryRowsWithConnectionContext is the query method with given context and connection
+	// of sql which returns a cursor of the results.
+	QueryRowsWithConnectionContext(ctx context.Context, conn, query string, args ...interface{}) (*Rows, error)
+
+	// QueryInto is the query method of sql which scans the results into dst. The
+	// dst is a pointer to a slice of struct whose fields are mapped to the columns
+	// by the `db` tags, or a pointer to a struct which receives the first row.
+	QueryInto(dst interface{}, query string, args ...interface{}) error
+
+	// QueryIntoContext is the QueryInto method with the given context.
+	QueryIntoContext(ctx context.Context, dst interface{}, query string, args ...interface{}) error
+
 	// Transaction API
 	// ===================================
 
@@ -45,35 +82,143 @@ type Connection interface {
 
 	ExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error)
 
-	BeginTxWithReadUncommitted() *sql.Tx
-	BeginTxWithReadCommitted() *sql.Tx
-	BeginTxWithRepeatableRead() *sql.Tx
-	BeginTx() *sql.Tx
-	BeginTxWithLevel(level sql.IsolationLevel) *sql.Tx
-
-	BeginTxWithReadUncommittedAndConnection(conn string) *sql.Tx
-	BeginTxWithReadCommittedAndConnection(conn string) *sql.Tx
-	BeginTxWithRepeatableReadAndConnection(conn string) *sql.Tx
-	BeginTxAndConnection(conn string) *sql.Tx
-	BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) *sql.Tx
+	BeginTxWithReadUncommitted() (*sql.Tx, error)
+	BeginTxWithReadCommitted() (*sql.Tx, error)
+	BeginTxWithRepeatableRead() (*sql.Tx, error)
+	BeginTx() (*sql.Tx, error)
+	BeginTxWithLevel(level sql.IsolationLevel) (*sql.Tx, error)
+
+	BeginTxWithReadUncommittedAndConnection(conn string) (*sql.Tx, error)
+	BeginTxWithReadCommittedAndConnection(conn string) (*sql.Tx, error)
+	BeginTxWithRepeatableReadAndConnection(conn string) (*sql.Tx, error)
+	BeginTxAndConnection(conn string) (*sql.Tx, error)
+	BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) (*sql.Tx, error)
+
+	// Context-aware Transaction API
+	// ===================================
+
+	QueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error)
+
+	ExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error)
+
+	QueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error)
+
+	BeginTxContext(ctx context.Context) (*sql.Tx, error)
+	BeginTxWithLevelContext(ctx context.Context, level sql.IsolationLevel) (*sql.Tx, error)
+	BeginTxAndConnectionContext(ctx context.Context, conn string) (*sql.Tx, error)
+	BeginTxWithLevelAndConnectionContext(ctx context.Context, conn string, level sql.IsolationLevel) (*sql.Tx, error)
+
+	// Ping verifies that the connection conn is reachable.
+	Ping(ctx context.Context, conn string) error
+}
+
+// The optional features of Connection are the interfaces below, which the
+// built-in drivers implement. The Connection of other drivers can implement
+// them or not.
+
+// ReplicaRouter is the Connection which routes the reads to the replicas.
+type ReplicaRouter interface {
+	// ReadConnection get the name of the connection which the reads of the
+	// connection con are routed to, which is one of the replicas of it, or con
+	// itself if it has none.
+	ReadConnection(con string) string
+
+	// Written marks the connection con as written, after which the reads of
+	// it are routed to the primary for the Database.StickyPrimary duration.
+	Written(con string)
 }
 
-// GetConnectionByDriver return the Connection by given driver name.
-func GetConnectionByDriver(driver string) Connection {
-	switch driver {
-	case DriverMysql:
-		return GetMysqlDB()
-	case DriverMssql:
-		return GetMssqlDB()
-	case DriverSqlite:
-		return GetSqliteDB()
-	case DriverPostgresql:
-		return GetPostgresqlDB()
-	default:
-		panic("driver not found!")
+// HealthChecker is the Connection which checks the health of the connections.
+type HealthChecker interface {
+	// HealthCheck pings all the connections and replicas, and returns the
+	// status of them keyed by the names. The replicas which are unhealthy are
+	// removed from the routing of reads until they recover.
+	HealthCheck() map[string]HealthStatus
+
+	// Health get the status of the last HealthCheck, e.g. for the readiness
+	// probes.
+	Health() map[string]HealthStatus
+
+	// StartHealthCheck runs HealthCheck every interval in the background
+	// until StopHealthCheck is called. DefaultHealthCheckInterval is used if
+	// interval is not positive.
+	StartHealthCheck(interval time.Duration)
+
+	// StopHealthCheck stops the background HealthCheck.
+	StopHealthCheck()
+}
+
+// StmtCacher is the Connection which caches the prepared statements.
+type StmtCacher interface {
+	// StmtCache get the prepared statement cache of the connection, or nil if
+	// it is disabled.
+	StmtCache(con string) *StmtCache
+}
+
+var (
+	_ ReplicaRouter = (*Base)(nil)
+	_ HealthChecker = (*Mysql)(nil)
+	_ HealthChecker = (*Mssql)(nil)
+	_ HealthChecker = (*Postgresql)(nil)
+	_ HealthChecker = (*Sqlite)(nil)
+	_ StmtCacher    = (*Base)(nil)
+)
+
+// Factory returns a new Connection of the driver.
+type Factory func() Connection
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Factory)
+)
+
+func init() {
+	Register(DriverMysql, MysqlConnection, nil)
+	Register(DriverMssql, MssqlConnection, nil)
+	Register(DriverSqlite, SqliteConnection, nil)
+	Register(DriverPostgresql, PostgresqlConnection, nil)
+}
+
+// Register makes a driver available by the given name, so that it can be got by
+// GetConnectionByDriver and the SQL builder renders the statements of its
+// Connection with the Dialect d. The Dialect can be nil if it is registered by
+// dialect.Register already. Registering the same name again replaces the former one.
+func Register(name string, factory Factory, d dialect.Dialect) {
+	if factory == nil {
+		panic("connection: Register factory is nil")
+	}
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[name] = factory
+	if d != nil {
+		dialect.Register(name, d)
 	}
 }
 
+// Drivers returns a sorted list of the names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	list := make([]string, 0, len(drivers))
+	for name := range drivers {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// GetConnectionByDriver return the Connection by given driver name, or
+// ErrDriverNotFound if the driver is not registered.
+func GetConnectionByDriver(driver string) (Connection, error) {
+	driversMu.RLock()
+	factory, ok := drivers[driver]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, ErrDriverNotFound
+	}
+	return factory(), nil
+}
+
 func MysqlConnection() Connection {
 	return GetMysqlDB()
 }
@@ -101,6 +246,35 @@ type Database struct {
 	Params     Params
 	MaxIdleCon int
 	MaxOpenCon int
+
+	// StmtCacheSize is the maximum number of prepared statements cached for
+	// the connection, which disables the cache if it is zero. The statements
+	// within the transactions are not cached.
+	StmtCacheSize int
+
+	// Converters are the converters of the result values of the connection,
+	// which take precedence over the ones of RegisterConverter. Within the
+	// transactions, they apply by the connection given by TxContext, which
+	// the SQL builder sets, or by the default connection like the other
+	// methods without the connection name.
+	Converters Converters
+
+	// Replicas are the read replicas of the connection, which is the primary
+	// of them. The reads of the SQL builder are routed to the replicas by
+	// ReplicaPolicy, and the writes and transactions to the primary. The
+	// StmtCacheSize and Converters of the primary are used by the replicas
+	// which leave them empty.
+	Replicas []Database
+
+	// ReplicaPolicy is the policy of picking the replica, which is RoundRobin
+	// if it is empty.
+	ReplicaPolicy ReplicaPolicy
+
+	// StickyPrimary is the duration for which the reads of the SQL builder
+	// are routed to the primary after a write of it, so that they see the
+	// rows which the replicas may not have yet. It is shared by all the
+	// callers of the connection, and zero disables it.
+	StickyPrimary time.Duration
 }
 
 type Params map[string]string
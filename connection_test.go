@@ -0,0 +1,42 @@
+package connection
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chenhg5/go-sql/dialect"
+)
+
+func TestRegister(t *testing.T) {
+	Register("recorder", func() Connection {
+		return &recordConn{driver: "recorder"}
+	}, dialect.GetDialectByDriver(DriverPostgresql))
+
+	drivers := Drivers()
+	want := []string{DriverMssql, DriverMysql, DriverPostgresql, "recorder", DriverSqlite}
+	if !reflect.DeepEqual(drivers, want) {
+		t.Errorf("Drivers() = %v, want %v", drivers, want)
+	}
+
+	conn, err := GetConnectionByDriver("recorder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WithDriver(conn).Table("users").Where("id", "=", 1).All(); err != nil {
+		t.Fatal(err)
+	}
+	statements := conn.(*recordConn).statements
+	if want := `select * from "users" where "id" = $1`; len(statements) != 1 || statements[0] != want {
+		t.Errorf("statements = %q, want the dialect of the registered driver %q", statements, want)
+	}
+
+	if _, err := GetConnectionByDriver("missing"); err != ErrDriverNotFound {
+		t.Errorf("err = %v, want ErrDriverNotFound", err)
+	}
+	for _, driver := range []string{DriverMysql, DriverMssql, DriverSqlite, DriverPostgresql} {
+		conn, err := GetConnectionByDriver(driver)
+		if err != nil || conn.Name() != driver {
+			t.Errorf("GetConnectionByDriver(%q) = %v, %v", driver, conn, err)
+		}
+	}
+}
@@ -2,78 +2,391 @@ package connection
 
 import (
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-// SetColVarType set the column type.
-func SetColVarType(colVar *[]interface{}, i int, typeName string) {
-	switch {
-	case Contains(DT(typeName), BoolTypeList):
-		var s sql.NullBool
-		(*colVar)[i] = &s
-	case Contains(DT(typeName), IntTypeList):
-		var s sql.NullInt64
-		(*colVar)[i] = &s
-	case Contains(DT(typeName), FloatTypeList):
-		var s sql.NullFloat64
-		(*colVar)[i] = &s
-	case Contains(DT(typeName), UintTypeList):
-		var s []uint8
-		(*colVar)[i] = &s
-	case Contains(DT(typeName), StringTypeList):
-		var s sql.NullString
-		(*colVar)[i] = &s
+// scanKind is the kind of value which a column is scanned into.
+type scanKind uint8
+
+const (
+	scanAny scanKind = iota
+	scanBool
+	scanInt
+	scanFloat
+	scanBytes
+	scanString
+)
+
+// scanKinds maps the DatabaseType to the scanKind of it, which is built from
+// the type lists once instead of scanning them for every value.
+var scanKinds = func() map[DatabaseType]scanKind {
+	kinds := make(map[DatabaseType]scanKind)
+	// the former lists win as the switches did
+	for _, list := range []struct {
+		types []DatabaseType
+		kind  scanKind
+	}{
+		{StringTypeList, scanString},
+		{UintTypeList, scanBytes},
+		{FloatTypeList, scanFloat},
+		{IntTypeList, scanInt},
+		{BoolTypeList, scanBool},
+	} {
+		for _, typ := range list.types {
+			kinds[typ] = list.kind
+		}
+	}
+	return kinds
+}()
+
+// scanKindOf return the scanKind of the database type name.
+func scanKindOf(typeName string) scanKind {
+	return scanKinds[DatabaseType(typeName)]
+}
+
+// normalizeTypeName turns the type name of column into the form of
+// DatabaseType, e.g. "varchar(255)" to "VARCHAR" and "double precision" to
+// "DOUBLEPRECISION".
+func normalizeTypeName(name string) string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if strings.IndexByte(name, ' ') >= 0 {
+		name = strings.Replace(name, " ", "", -1)
+	}
+	return name
+}
+
+// columnScanner is the scan destination of a column, which is reused across
+// the rows, and the conversion of it into the result value.
+type columnScanner struct {
+	dest  interface{}
+	value func() interface{}
+}
+
+// newColumnScanner return the columnScanner of the database type name.
+func newColumnScanner(typeName string) columnScanner {
+	switch scanKindOf(typeName) {
+	case scanBool:
+		var v sql.NullBool
+		return columnScanner{dest: &v, value: func() interface{} {
+			if v.Valid {
+				return v.Bool
+			}
+			return nil
+		}}
+	case scanInt:
+		var v sql.NullInt64
+		return columnScanner{dest: &v, value: func() interface{} {
+			if v.Valid {
+				return v.Int64
+			}
+			return nil
+		}}
+	case scanFloat:
+		var v sql.NullFloat64
+		return columnScanner{dest: &v, value: func() interface{} {
+			if v.Valid {
+				return v.Float64
+			}
+			return nil
+		}}
+	case scanString:
+		var v sql.NullString
+		return columnScanner{dest: &v, value: func() interface{} {
+			if v.Valid {
+				return v.String
+			}
+			return nil
+		}}
+	case scanBytes:
+		// the bytes are copied by Scan, and the drivers which return other
+		// types for the decimal, e.g. float64 of sqlite, are kept as they are
+		var v interface{}
+		return columnScanner{dest: &v, value: func() interface{} {
+			if v == nil {
+				return []uint8(nil)
+			}
+			return v
+		}}
 	default:
-		var s interface{}
-		(*colVar)[i] = &s
+		var v interface{}
+		return columnScanner{dest: &v, value: func() interface{} {
+			return v
+		}}
 	}
 }
 
+// SetColVarType set the column type.
+func SetColVarType(colVar *[]interface{}, i int, typeName string) {
+	(*colVar)[i] = newColumnScanner(typeName).dest
+}
+
 // SetResultValue set the result value.
 func SetResultValue(result *map[string]interface{}, index string, colVar interface{}, typeName string) {
-	switch {
-	case Contains(DT(typeName), BoolTypeList):
-		temp := *(colVar.(*sql.NullBool))
-		if temp.Valid {
-			(*result)[index] = temp.Bool
+	switch v := colVar.(type) {
+	case *sql.NullBool:
+		if v.Valid {
+			(*result)[index] = v.Bool
 		} else {
 			(*result)[index] = nil
 		}
-	case Contains(DT(typeName), IntTypeList):
-		temp := *(colVar.(*sql.NullInt64))
-		if temp.Valid {
-			(*result)[index] = temp.Int64
+	case *sql.NullInt64:
+		if v.Valid {
+			(*result)[index] = v.Int64
 		} else {
 			(*result)[index] = nil
 		}
-	case Contains(DT(typeName), FloatTypeList):
-		temp := *(colVar.(*sql.NullFloat64))
-		if temp.Valid {
-			(*result)[index] = temp.Float64
+	case *sql.NullFloat64:
+		if v.Valid {
+			(*result)[index] = v.Float64
 		} else {
 			(*result)[index] = nil
 		}
-	case Contains(DT(typeName), UintTypeList):
-		(*result)[index] = *(colVar.(*[]uint8))
-	case Contains(DT(typeName), StringTypeList):
-		temp := *(colVar.(*sql.NullString))
-		if temp.Valid {
-			(*result)[index] = temp.String
+	case *sql.NullString:
+		if v.Valid {
+			(*result)[index] = v.String
 		} else {
 			(*result)[index] = nil
 		}
+	case *[]uint8:
+		(*result)[index] = *v
+	case *interface{}:
+		if *v == nil && scanKindOf(typeName) == scanBytes {
+			(*result)[index] = []uint8(nil)
+		} else {
+			(*result)[index] = *v
+		}
+	}
+}
+
+// Converter converts the value of a column read from the driver into the
+// result value. It is not called for the NULL values.
+type Converter func(value interface{}) (interface{}, error)
+
+// Converters maps the DatabaseType to the Converter of it.
+type Converters map[DatabaseType]Converter
+
+var (
+	convertersMu sync.RWMutex
+	converters   = make(Converters)
+)
+
+// RegisterConverter registers the converter of the database type for all the
+// connections, which is overridden by Database.Converters of a connection. A
+// nil converter removes the registered one.
+//
+//	connection.RegisterConverter(connection.Decimal, connection.DecimalStringConverter)
+func RegisterConverter(typ DatabaseType, converter Converter) {
+	typ = DatabaseType(normalizeTypeName(string(typ)))
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	if converter == nil {
+		delete(converters, typ)
+		return
+	}
+	converters[typ] = converter
+}
+
+// converterOf return the converter of the database type name, looking up the
+// local converters of the connection before the registered ones.
+func converterOf(local Converters, typeName string) Converter {
+	typ := DatabaseType(typeName)
+	if converter, ok := local[typ]; ok {
+		return converter
+	}
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	return converters[typ]
+}
+
+// TimeLayouts are the layouts of the time values returned as text by the
+// drivers, e.g. mysql without parseTime and sqlite.
+var TimeLayouts = map[string][]string{
+	DriverMysql: {
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+		"15:04:05.999999999",
+	},
+	DriverSqlite: {
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	},
+	DriverPostgresql: {
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z07",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+		"15:04:05.999999999",
+	},
+	DriverMssql: {
+		"2006-01-02 15:04:05.9999999 -07:00",
+		"2006-01-02 15:04:05.9999999",
+		"2006-01-02",
+		"15:04:05.9999999",
+	},
+}
+
+// TimeConverter return the converter which parses the text of the time values
+// of the driver into time.Time with TimeLayouts. The time.Time values are kept
+// as they are.
+//
+//	connection.RegisterConverter(connection.Datetime, connection.TimeConverter(connection.DriverMysql))
+func TimeConverter(driver string) Converter {
+	layouts := TimeLayouts[driver]
+	return func(value interface{}) (interface{}, error) {
+		var s string
+		switch v := value.(type) {
+		case time.Time:
+			return v, nil
+		case []byte:
+			s = string(v)
+		case string:
+			s = v
+		default:
+			return nil, fmt.Errorf("convert %T to time", value)
+		}
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("parse time %q", s)
+	}
+}
+
+// decimalString return the exact text of the decimal value.
+func decimalString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
 	default:
-		if colVar2, ok := colVar.(*interface{}); ok {
-			if colVar, ok = (*colVar2).(int64); ok {
-				(*result)[index] = colVar
-			} else if colVar, ok = (*colVar2).(string); ok {
-				(*result)[index] = colVar
-			} else if colVar, ok = (*colVar2).(float64); ok {
-				(*result)[index] = colVar
-			} else if colVar, ok = (*colVar2).([]uint8); ok {
-				(*result)[index] = colVar
-			} else {
-				(*result)[index] = colVar
+		return "", fmt.Errorf("convert %T to decimal", value)
+	}
+}
+
+// DecimalStringConverter converts the decimal values into strings without the
+// loss of precision of float64.
+func DecimalStringConverter(value interface{}) (interface{}, error) {
+	return decimalString(value)
+}
+
+// DecimalRatConverter converts the decimal values into *big.Rat.
+func DecimalRatConverter(value interface{}) (interface{}, error) {
+	s, err := decimalString(value)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("parse decimal %q", s)
+	}
+	return r, nil
+}
+
+// JSONConverter decodes the JSON values as encoding/json does into
+// interface{}, e.g. the objects into map[string]interface{} and the arrays
+// into []interface{}.
+func JSONConverter(value interface{}) (interface{}, error) {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, fmt.Errorf("convert %T to json", value)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// UUIDConverter converts the UUID values, in the text or the 16 bytes form,
+// into the lower case text form. The bytes are in the RFC 4122 order, which
+// is not the one of the mssql UNIQUEIDENTIFIER, see MssqlUUIDConverter.
+func UUIDConverter(value interface{}) (interface{}, error) {
+	return formatUUID(value, false)
+}
+
+// MssqlUUIDConverter is the UUIDConverter of the mssql UNIQUEIDENTIFIER,
+// whose first three groups are little-endian.
+func MssqlUUIDConverter(value interface{}) (interface{}, error) {
+	return formatUUID(value, true)
+}
+
+func formatUUID(value interface{}, mixedEndian bool) (interface{}, error) {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		if len(v) == 16 {
+			b := make([]byte, 16)
+			copy(b, v)
+			if mixedEndian {
+				b[0], b[1], b[2], b[3] = b[3], b[2], b[1], b[0]
+				b[4], b[5] = b[5], b[4]
+				b[6], b[7] = b[7], b[6]
 			}
+			h := hex.EncodeToString(b)
+			return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
 		}
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return nil, fmt.Errorf("convert %T to uuid", value)
+	}
+	s = strings.ToLower(strings.Trim(s, "{}"))
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return nil, fmt.Errorf("parse uuid %q", s)
+	}
+	if _, err := hex.DecodeString(strings.Replace(s, "-", "", -1)); err != nil {
+		return nil, fmt.Errorf("parse uuid %q", s)
+	}
+	return s, nil
+}
+
+// BoolConverter converts the integer values, e.g. of mysql TINYINT(1), and the
+// single byte of mysql BIT(1) into bool. The drivers do not report the display width of TINYINT, so it is
+// selected per connection where all the TINYINT columns are booleans:
+//
+//	connection.Database{Converters: connection.Converters{connection.Tinyint: connection.BoolConverter}}
+func BoolConverter(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case []byte:
+		if len(v) == 1 && v[0] <= 1 {
+			// BIT(1)
+			return v[0] == 1, nil
+		}
+		return strconv.ParseBool(string(v))
+	case string:
+		return strconv.ParseBool(v)
+	default:
+		return nil, fmt.Errorf("convert %T to bool", value)
 	}
 }
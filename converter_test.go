@@ -0,0 +1,161 @@
+package connection
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"varchar(255)", "VARCHAR"},
+		{"decimal(10, 2)", "DECIMAL"},
+		{"double precision", "DOUBLEPRECISION"},
+		{" int ", "INT"},
+	}
+	for _, tt := range tests {
+		if got := normalizeTypeName(tt.name); got != tt.want {
+			t.Errorf("normalizeTypeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConverters(t *testing.T) {
+	tests := []struct {
+		name      string
+		converter Converter
+		value     interface{}
+		want      interface{}
+		err       bool
+	}{
+		{name: "json object", converter: JSONConverter, value: []byte(`{"a":1}`),
+			want: map[string]interface{}{"a": float64(1)}},
+		{name: "json array", converter: JSONConverter, value: `[1,"a"]`,
+			want: []interface{}{float64(1), "a"}},
+		{name: "json scalar", converter: JSONConverter, value: []byte(`"a"`), want: "a"},
+		{name: "json null", converter: JSONConverter, value: []byte(`null`), want: nil},
+		{name: "json invalid", converter: JSONConverter, value: []byte(`{`), err: true},
+		{name: "json type", converter: JSONConverter, value: int64(1), err: true},
+
+		{name: "decimal bytes", converter: DecimalStringConverter, value: []byte("1.10"), want: "1.10"},
+		{name: "decimal float", converter: DecimalStringConverter, value: 1.5, want: "1.5"},
+		{name: "decimal rat", converter: DecimalRatConverter, value: []byte("0.1"), want: big.NewRat(1, 10)},
+		{name: "decimal rat invalid", converter: DecimalRatConverter, value: "a", err: true},
+
+		{name: "uuid text", converter: UUIDConverter, value: "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}",
+			want: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{name: "uuid bytes", converter: UUIDConverter,
+			value: []byte{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+			want:  "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{name: "mssql uuid bytes", converter: MssqlUUIDConverter,
+			value: []byte{0x10, 0xb8, 0xa7, 0x6b, 0xad, 0x9d, 0xd1, 0x11, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8},
+			want:  "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{name: "uuid invalid", converter: UUIDConverter, value: "6ba7b810", err: true},
+
+		{name: "bool int", converter: BoolConverter, value: int64(2), want: true},
+		{name: "bool bytes", converter: BoolConverter, value: []byte("0"), want: false},
+		{name: "bool text", converter: BoolConverter, value: []byte("1"), want: true},
+		{name: "bool bit", converter: BoolConverter, value: []byte{0x01}, want: true},
+		{name: "bool bit zero", converter: BoolConverter, value: []byte{0x00}, want: false},
+
+		{name: "time text", converter: TimeConverter(DriverSqlite), value: "2020-01-02 03:04:05",
+			want: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{name: "time invalid", converter: TimeConverter(DriverSqlite), value: "a", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.converter(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if tt.err {
+				return
+			}
+			if r, ok := got.(*big.Rat); ok {
+				if r.Cmp(tt.want.(*big.Rat)) != 0 {
+					t.Errorf("got %v, want %v", r, tt.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalConverters(t *testing.T) {
+	conn := openSqlite(t, Database{Converters: Converters{"decimal(10,2)": DecimalRatConverter}})
+	mustExec(t, conn,
+		"create table orders (id integer primary key, amount decimal(10,2))",
+		"insert into orders (amount) values ('1.25'), ('2.5')",
+	)
+
+	res, err := conn.Query("select amount from orders order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := res[0]["amount"].(*big.Rat); !ok || r.Cmp(big.NewRat(5, 4)) != 0 {
+		t.Errorf("amount = %#v, want 5/4", res[0]["amount"])
+	}
+
+	sum, err := WithDriver(conn).Table("orders").Sum("amount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3.75 {
+		t.Errorf("sum = %v, want 3.75", sum)
+	}
+}
+
+func TestSumDecimalString(t *testing.T) {
+	conn := openSqlite(t, Database{Converters: Converters{Decimal: DecimalStringConverter}})
+	mustExec(t, conn,
+		"create table orders (id integer primary key, amount decimal)",
+		"insert into orders (amount) values ('1.25'), ('2.5')",
+	)
+
+	sum, err := WithDriver(conn).Table("orders").Sum("amount")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3.75 {
+		t.Errorf("sum = %v, want 3.75", sum)
+	}
+
+	sum, err = WithDriver(conn).Table("orders").Where("id", ">", 2).Sum("amount")
+	if err != nil || sum != 0 {
+		t.Errorf("sum of no rows = %v, %v, want 0", sum, err)
+	}
+}
+
+func TestNumericKeepsFractions(t *testing.T) {
+	tests := []struct {
+		name       string
+		converters Converters
+		want       interface{}
+	}{
+		{name: "driver value", want: 1.25},
+		{name: "decimal string", converters: Converters{Numeric: DecimalStringConverter}, want: "1.25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openSqlite(t, Database{Converters: tt.converters})
+			mustExec(t, conn,
+				"create table prices (amount numeric(10,2))",
+				"insert into prices values (1.25)",
+			)
+			res, err := conn.Query("select amount from prices")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res[0]["amount"] != tt.want {
+				t.Errorf("amount = %#v, want %#v", res[0]["amount"], tt.want)
+			}
+		})
+	}
+}
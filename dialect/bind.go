@@ -0,0 +1,144 @@
+package dialect
+
+import "strings"
+
+// bind appends the arguments of expr and replaces the "?" placeholders of it
+// with the ones of the dialect. The escaped "??" is kept for the driver which
+// rebinds the statements, or unescaped to "?" otherwise.
+func (sql *SQLComponent) bind(c commonDialect, expr string, args []interface{}) string {
+	if c.placeholder != "" || !c.rebound {
+		expr = rebind(expr, c.Placeholder, len(sql.Args), !c.rebound)
+	}
+	sql.Args = append(sql.Args, args...)
+	return expr
+}
+
+// bindValue appends the argument and return the placeholder of it.
+func (sql *SQLComponent) bindValue(c commonDialect, arg interface{}) string {
+	sql.Args = append(sql.Args, arg)
+	return c.Placeholder(len(sql.Args))
+}
+
+// Rebind replaces the "?" placeholders of the raw query with the ones of the
+// dialect, e.g. "$1" of postgresql, and unescapes "??" to a literal "?", such
+// as the json operator of postgresql. The quoted strings, identifiers and
+// comments are left as they are.
+func (c commonDialect) Rebind(query string) string {
+	return rebind(query, c.Placeholder, 0, true)
+}
+
+// rebind replaces the "?" placeholders of query, except the ones in quoted
+// strings, identifiers and comments, with placeholder(offset+1),
+// placeholder(offset+2) and so on. The "??" is unescaped to "?" if unescape
+// is true, or kept otherwise.
+func rebind(query string, placeholder func(i int) string, offset int, unescape bool) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var (
+		b = strings.Builder{}
+		n = offset
+	)
+	b.Grow(len(query) + 8)
+	for i := 0; i < len(query); i++ {
+		if end := skipQuoted(query, i); end > i {
+			b.WriteString(query[i:end])
+			i = end - 1
+			continue
+		}
+		if query[i] != '?' {
+			b.WriteByte(query[i])
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == '?' {
+			if unescape {
+				b.WriteByte('?')
+			} else {
+				b.WriteString("??")
+			}
+			i++
+			continue
+		}
+		n++
+		b.WriteString(placeholder(n))
+	}
+	return b.String()
+}
+
+// skipQuoted return the end of the quoted string, identifier or comment which
+// starts at query[i], or i if there is none. The unterminated one ends at the
+// end of query.
+func skipQuoted(query string, i int) int {
+	end := -1
+	switch ch := query[i]; {
+	case (ch == 'E' || ch == 'e') && i+1 < len(query) && query[i+1] == '\'' &&
+		(i == 0 || !isIdentByte(query[i-1])):
+		// escape string of postgresql, e.g. E'it\'s', where the backslash
+		// escapes the next character
+		for j := i + 2; j < len(query); j++ {
+			switch query[j] {
+			case '\\':
+				j++
+			case '\'':
+				if j+1 < len(query) && query[j+1] == '\'' {
+					j++
+					continue
+				}
+				return j + 1
+			}
+		}
+		return len(query)
+	case ch == '\'' || ch == '"' || ch == '`':
+		// a doubled quote is an escaped one, which is skipped as two strings
+		if end = strings.IndexByte(query[i+1:], ch); end >= 0 {
+			end += i + 2
+		}
+	case ch == '-' && strings.HasPrefix(query[i:], "--"):
+		if end = strings.IndexByte(query[i:], '\n'); end >= 0 {
+			end += i + 1
+		}
+	case ch == '/' && strings.HasPrefix(query[i:], "/*"):
+		if end = strings.Index(query[i+2:], "*/"); end >= 0 {
+			end += i + 4
+		}
+	case ch == '$':
+		// dollar quoted string of postgresql, e.g. $$text$$ or $tag$text$tag$
+		tag := dollarTag(query[i:])
+		if tag == "" {
+			return i
+		}
+		if end = strings.Index(query[i+len(tag):], tag); end >= 0 {
+			end += i + 2*len(tag)
+		}
+	default:
+		return i
+	}
+	if end < 0 {
+		return len(query)
+	}
+	return end
+}
+
+// isIdentByte reports whether ch can be a byte of an unquoted identifier.
+func isIdentByte(ch byte) bool {
+	return ch == '_' || ch == '$' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' ||
+		'0' <= ch && ch <= '9' || ch >= 0x80
+}
+
+// dollarTag return the opening tag of dollar quoted string at the start of s,
+// or empty if it is not, e.g. a positional placeholder "$1".
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '$':
+			return s[:i+1]
+		case ch == '_' || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch >= 0x80:
+		case '0' <= ch && ch <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
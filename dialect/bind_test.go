@@ -0,0 +1,57 @@
+package dialect
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		driver string
+		query  string
+		want   string
+	}{
+		{"postgresql", "select * from t where a = ? and b = ?", "select * from t where a = $1 and b = $2"},
+		{"mssql", "select * from t where a = ? and b = ?", "select * from t where a = @p1 and b = @p2"},
+		{"mysql", "select * from t where a = ? and b = ?", "select * from t where a = ? and b = ?"},
+		{"postgresql", "select * from t", "select * from t"},
+		{"postgresql", "select data ?? 'k' from t where a = ?", "select data ? 'k' from t where a = $1"},
+		{"postgresql", "select '?', \"?\", `?` from t where a = ?", "select '?', \"?\", `?` from t where a = $1"},
+		{"postgresql", "select 'it''s ?' where a = ?", "select 'it''s ?' where a = $1"},
+		{"postgresql", "select E'it\\'s ?' where a = ?", "select E'it\\'s ?' where a = $1"},
+		{"postgresql", "select e'a\\\\' where a = ?", "select e'a\\\\' where a = $1"},
+		{"postgresql", "select E'it''s ?' where a = ?", "select E'it''s ?' where a = $1"},
+		{"postgresql", "select name' ?' where a = ?", "select name' ?' where a = $1"},
+		{"postgresql", "select 1 -- ?\nwhere a = ?", "select 1 -- ?\nwhere a = $1"},
+		{"postgresql", "select /* ? */ 1 where a = ?", "select /* ? */ 1 where a = $1"},
+		{"postgresql", "select $$?$$, $tag$ ? $tag$ where a = ?", "select $$?$$, $tag$ ? $tag$ where a = $1"},
+		{"postgresql", "select 'unterminated ?", "select 'unterminated ?"},
+	}
+	for _, tt := range tests {
+		if got := GetDialectByDriver(tt.driver).Rebind(tt.query); got != tt.want {
+			t.Errorf("%s Rebind(%q) = %q, want %q", tt.driver, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestBindEscape(t *testing.T) {
+	// the "??" is kept only for the driver which rebinds the statements
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{"postgresql", "a ?? b and c = $1"},
+		{"mssql", "a ? b and c = @p1"},
+		{"mysql", "a ? b and c = ?"},
+		{"sqlite", "a ? b and c = ?"},
+	}
+	for _, tt := range tests {
+		statement, err := GetDialectByDriver(tt.driver).Select(&SQLComponent{
+			TableName: "t",
+			Fields:    []Field{{Raw: "a ?? b and c = ?", Args: []interface{}{2}}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "select " + tt.want + " from " + GetDialectByDriver(tt.driver).QuoteIdent("t"); statement != want {
+			t.Errorf("%s bind = %q, want %q", tt.driver, statement, want)
+		}
+	}
+}
@@ -1,35 +1,165 @@
 package dialect
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type commonDialect struct {
-	delimiter string
+	delimiter       string
+	maxPlaceholders int
+	// closeDelimiter is the closing delimiter if it differs from the opening
+	// one, e.g. "]" of mssql.
+	closeDelimiter string
+	// placeholder is the prefix of the numbered placeholders, e.g. "@p" of
+	// mssql, or empty for "?".
+	placeholder string
+	// offsetFetch renders the limit and offset by "top (n)" and
+	// "offset m rows fetch next n rows only" instead of "limit n offset m".
+	offsetFetch bool
+	// likeEscape is the escape character of like operation which is not the default
+	// backslash of the database and must be declared by the escape clause.
+	likeEscape string
+	// castDate casts the column to date instead of calling the date function.
+	castDate bool
+	// fullJoin reports whether the full join is supported.
+	fullJoin bool
+	// intersectExcept reports whether the intersect and except are supported.
+	intersectExcept bool
+	// compoundParens reports whether the members of compound select can be
+	// parenthesized to have their own order and limit.
+	compoundParens bool
+	// recursiveKeyword reports whether the recursive common table expression
+	// needs the "recursive" keyword.
+	recursiveKeyword bool
+	// random is the function of random order, e.g. "rand()".
+	random string
+	// nullsOrder reports whether "nulls first" and "nulls last" are supported,
+	// which are emulated by ordering the null flag otherwise.
+	nullsOrder bool
+	// returning is the clause which returns the inserted column: "returning"
+	// appended to the statement, "output" of mssql before the values, or empty
+	// if it is not supported.
+	returning string
+	// maxRows is the maximum number of rows in the values of one insert
+	// statement, or zero if it is only limited by maxPlaceholders.
+	maxRows int
+	// rebound reports whether the statements are rebound by the driver, which
+	// unescapes the "??" kept by bind, e.g. postgresql.
+	rebound bool
 }
 
-func (c commonDialect) Insert(comp *SQLComponent) string {
-	comp.prepareInsert(c.delimiter)
-	return comp.Statement
+func (c commonDialect) Insert(comp *SQLComponent) (string, error) {
+	if c.returning == "" {
+		comp.Returning = ""
+	}
+	if err := comp.prepareInsert(c); err != nil {
+		return "", err
+	}
+	if comp.Returning != "" && c.returning == "returning" {
+		comp.Statement += " returning " + c.wrap(comp.Returning)
+	}
+	return comp.Statement, nil
 }
 
-func (c commonDialect) Delete(comp *SQLComponent) string {
-	comp.Statement = "delete from " + comp.TableName + comp.getWheres(c.delimiter)
-	return comp.Statement
+func (c commonDialect) InsertBatch(comp *SQLComponent) (string, error) {
+	if err := comp.prepareInsertBatch(c); err != nil {
+		return "", err
+	}
+	return comp.Statement, nil
 }
 
-func (c commonDialect) Update(comp *SQLComponent) string {
-	comp.prepareUpdate(c.delimiter)
-	return comp.Statement
+func (c commonDialect) Upsert(comp *SQLComponent) (string, error) {
+	return "", ErrUnsupported
 }
 
-func (c commonDialect) Count(comp *SQLComponent) string {
-	comp.prepareUpdate(c.delimiter)
-	return comp.Statement
+// upsertOnConflict renders the upsert in the form of "insert ... on conflict ... do update".
+func (c commonDialect) upsertOnConflict(comp *SQLComponent) (string, error) {
+	if len(comp.ConflictColumns) == 0 {
+		return "", ErrEmptyConflict
+	}
+	if err := comp.prepareInsert(c); err != nil {
+		return "", err
+	}
+
+	comp.Statement += " on conflict (" + c.wrapColumns(comp.ConflictColumns, "") + ")"
+
+	columns := comp.upsertColumns()
+	if len(columns) == 0 {
+		comp.Statement += " do nothing"
+		return comp.Statement, nil
+	}
+
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = c.wrap(col) + " = excluded." + c.wrap(col)
+	}
+	comp.Statement += " do update set " + strings.Join(sets, ", ")
+	return comp.Statement, nil
+}
+
+func (c commonDialect) Delete(comp *SQLComponent) (string, error) {
+	comp.Args = make([]interface{}, 0)
+	comp.err = nil
+	comp.Statement = "delete from " + c.QuoteIdent(comp.TableName) + comp.getWheres(c)
+	if comp.err != nil {
+		return "", comp.err
+	}
+	return comp.Statement, nil
 }
 
-func (c commonDialect) Select(comp *SQLComponent) string {
-	comp.Statement = "select " + comp.getFields(c.delimiter) + " from " + comp.TableName + comp.getJoins(c.delimiter) +
-		comp.getWheres(c.delimiter) + comp.getGroupBy() + comp.getOrderBy() + comp.getLimit() + comp.getOffset()
-	return comp.Statement
+func (c commonDialect) Update(comp *SQLComponent) (string, error) {
+	comp.err = nil
+	if err := comp.prepareUpdate(c); err != nil {
+		return "", err
+	}
+	if comp.err != nil {
+		return "", comp.err
+	}
+	return comp.Statement, nil
+}
+
+func (c commonDialect) Count(comp *SQLComponent) (string, error) {
+	if err := comp.prepareUpdate(c); err != nil {
+		return "", err
+	}
+	return comp.Statement, nil
+}
+
+func (c commonDialect) Select(comp *SQLComponent) (string, error) {
+	comp.Args = make([]interface{}, 0)
+	comp.err = nil
+	comp.Statement = c.selectStatement(comp)
+	if comp.err != nil {
+		return "", comp.err
+	}
+	return comp.Statement, nil
+}
+
+// selectStatement renders the select statement and appends the arguments to comp.Args.
+func (c commonDialect) selectStatement(comp *SQLComponent) string {
+	top := ""
+	if c.offsetFetch && comp.useTop() {
+		top = "top (" + comp.Limit + ") "
+	}
+	ctes := comp.getCtes(c)
+	statement := "select " + top + comp.getFields(c) + " from " + comp.getTable(c) + comp.getJoins(c) +
+		comp.getWheres(c) + comp.getGroupBy(c) + comp.getHavings(c) + comp.getUnions(c)
+	if c.offsetFetch && len(comp.Unions) > 0 && len(comp.Orders) == 0 && (comp.Limit != "" || comp.Offset != "") {
+		// the order by of compound select can only have the selected columns,
+		// so it is paged as a derived table without the order
+		statement = "select * from (" + statement + ") as " + c.wrap("compound")
+	}
+	return ctes + statement + comp.getOrderBy(c) + comp.getLimitOffset(c)
+}
+
+// date return the expression of the date part of field.
+func (c commonDialect) date(field string) string {
+	if c.castDate {
+		return "cast(" + field + " as date)"
+	}
+	return "date(" + field + ")"
 }
 
 func (c commonDialect) ShowColumns(table string) string {
@@ -44,6 +174,25 @@ func (c commonDialect) ShowTables() string {
 	return "show tables"
 }
 
+// Placeholder return the placeholder of the i-th argument, which starts from 1.
+func (c commonDialect) Placeholder(i int) string {
+	if c.placeholder == "" {
+		return "?"
+	}
+	return c.placeholder + strconv.Itoa(i)
+}
+
 func (c commonDialect) GetDelimiter() string {
 	return c.delimiter
 }
+
+func (c commonDialect) GetMaxPlaceholders() int {
+	if c.maxPlaceholders == 0 {
+		return 999
+	}
+	return c.maxPlaceholders
+}
+
+func (c commonDialect) GetMaxRows() int {
+	return c.maxRows
+}
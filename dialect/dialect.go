@@ -1,7 +1,10 @@
 package dialect
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // Dialect is methods set of different driver.
@@ -16,43 +19,96 @@ type Dialect interface {
 	ShowTables() string
 
 	// Insert
-	Insert(comp *SQLComponent) string
+	Insert(comp *SQLComponent) (string, error)
+
+	// InsertBatch
+	InsertBatch(comp *SQLComponent) (string, error)
+
+	// Upsert
+	Upsert(comp *SQLComponent) (string, error)
 
 	// Delete
-	Delete(comp *SQLComponent) string
+	Delete(comp *SQLComponent) (string, error)
 
 	// Update
-	Update(comp *SQLComponent) string
+	Update(comp *SQLComponent) (string, error)
 
 	// Select
-	Select(comp *SQLComponent) string
+	Select(comp *SQLComponent) (string, error)
 
 	// GetDelimiter return the delimiter of Dialect.
 	GetDelimiter() string
+
+	// GetMaxPlaceholders return the maximum number of placeholders in one statement.
+	GetMaxPlaceholders() int
+
+	// GetMaxRows return the maximum number of rows in the values of one insert
+	// statement, or zero if it is only limited by the placeholders.
+	GetMaxRows() int
+
+	// Placeholder return the placeholder of the i-th argument, which starts from 1,
+	// e.g. "?" of mysql, "$1" of postgresql and "@p1" of mssql.
+	Placeholder(i int) string
+
+	// Rebind replaces the "?" placeholders of raw query with the ones of Dialect.
+	Rebind(query string) string
+
+	// QuoteIdent quotes the identifier, e.g. "schema.table.column" or "users as u".
+	QuoteIdent(ident string) string
+}
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = make(map[string]Dialect)
+)
+
+func init() {
+	Register("mysql", mysql{
+		// INTERSECT and EXCEPT are supported since 8.0.31.
+		commonDialect: commonDialect{delimiter: "`", maxPlaceholders: 65535, compoundParens: true,
+			recursiveKeyword: true, random: "rand()"},
+	})
+	Register("mssql", mssql{
+		commonDialect: commonDialect{delimiter: "[", closeDelimiter: "]", maxPlaceholders: 2100,
+			placeholder: "@p", offsetFetch: true, likeEscape: `\`, castDate: true, fullJoin: true,
+			intersectExcept: true, compoundParens: true, random: "newid()", returning: "output",
+			// the row value expressions of one values list are at most 1000.
+			maxRows: 1000},
+	})
+	Register("postgresql", postgresql{
+		commonDialect: commonDialect{delimiter: `"`, maxPlaceholders: 65535, placeholder: "$",
+			castDate: true, fullJoin: true, intersectExcept: true, compoundParens: true, recursiveKeyword: true,
+			random: "random()", nullsOrder: true, returning: "returning", rebound: true},
+	})
+	Register("sqlite", sqlite{
+		// SQLITE_MAX_VARIABLE_NUMBER is 999 before 3.32.0 and 32766 since then.
+		commonDialect: commonDialect{delimiter: "`", maxPlaceholders: 999, likeEscape: `\`,
+			intersectExcept: true, recursiveKeyword: true, random: "random()",
+			// NULLS FIRST and NULLS LAST are supported since 3.30.0.
+			nullsOrder: true},
+	})
 }
 
-// GetDialectByDriver return the Dialect of given driver.
+// Register makes a Dialect available by the given driver name. Registering
+// the same name again replaces the former Dialect.
+func Register(driver string, d Dialect) {
+	if d == nil {
+		panic("dialect: Register dialect is nil")
+	}
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[driver] = d
+}
+
+// GetDialectByDriver return the Dialect of given driver, or the common
+// Dialect if the driver is not registered.
 func GetDialectByDriver(driver string) Dialect {
-	switch driver {
-	case "mysql":
-		return mysql{
-			commonDialect: commonDialect{delimiter: "`"},
-		}
-	case "mssql":
-		return mssql{
-			commonDialect: commonDialect{delimiter: "`"},
-		}
-	case "postgresql":
-		return postgresql{
-			commonDialect: commonDialect{delimiter: `"`},
-		}
-	case "sqlite":
-		return sqlite{
-			commonDialect: commonDialect{delimiter: "`"},
-		}
-	default:
-		return commonDialect{delimiter: "`"}
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	if d, ok := dialects[driver]; ok {
+		return d
 	}
+	return commonDialect{delimiter: "`"}
 }
 
 // H is a shorthand of map.
@@ -60,20 +116,54 @@ type H map[string]interface{}
 
 // SQLComponent is a sql components set.
 type SQLComponent struct {
-	Fields     []string
-	Functions  []string
+	Fields     []Field
 	TableName  string
 	Wheres     []Where
-	Leftjoins  []Join
+	Havings    []Where
+	Joins      []Join
 	Args       []interface{}
-	Order      string
-	Group      string
+	Orders     []Order
+	Groups     []Group
 	Offset     string
 	Limit      string
-	WhereRaws  string
 	UpdateRaws []RawUpdate
 	Statement  string
 	Values     H
+	Batch      []H
+
+	ConflictColumns []string
+	UpdateColumns   []string
+
+	// Returning is the column returned by the rows of Insert, e.g. the
+	// generated primary key. It is cleared by the dialects which can not
+	// return it, whose LastInsertId is used instead.
+	Returning string
+
+	// From is the subquery selected from instead of TableName, which is the alias of it.
+	From *SQLComponent
+	// Unions are the compound selects combined with the statement, which the
+	// order and limit are applied to.
+	Unions []Union
+	// Ctes are the common table expressions prefixed to the statement.
+	Ctes []Cte
+
+	// err is the first error occurred in rendering.
+	err error
+}
+
+// Field is a selected column or subquery.
+type Field struct {
+	Name string
+	// Function is the function applied to the column, e.g. "count".
+	Function string
+	// Alias is the name of the result column.
+	Alias string
+	// Sub is the subquery selected instead of Name.
+	Sub *SQLComponent
+	// Raw is the raw expression selected instead of Name.
+	Raw string
+	// Args are the arguments of the placeholders in Raw.
+	Args []interface{}
 }
 
 // Where contains the operation and field.
@@ -81,14 +171,73 @@ type Where struct {
 	Operation string
 	Field     string
 	Qmark     string
+	// Args are the arguments of the placeholders in Qmark or Raw.
+	Args []interface{}
+	// Or joins the condition with the previous one by "or" instead of "and".
+	Or bool
+	// Raw is the raw expression used instead of Field, Operation and Qmark.
+	Raw string
+	// Nested is the group of conditions wrapped in parentheses.
+	Nested []Where
+	// Column is the column compared with Field instead of the placeholders.
+	Column string
+	// Date compares the date part of Field.
+	Date bool
+	// Escape appends the escape clause of like operation if the dialect needs.
+	Escape bool
+	// Sub is the subquery operand, e.g. of the exists operation.
+	Sub *SQLComponent
 }
 
 // Join contains the table and field and operation.
 type Join struct {
+	// Type is the join type: "inner", "left", "right", "full" or "cross".
+	Type      string
 	Table     string
 	FieldA    string
 	Operation string
 	FieldB    string
+	// Sub is the subquery joined instead of Table, which is the alias of it.
+	Sub *SQLComponent
+	// Conditions are the on conditions used instead of FieldA, Operation and FieldB.
+	Conditions []Where
+}
+
+// Order is an item of order by.
+type Order struct {
+	Column string
+	// Direction is "asc" or "desc", or empty for the default of database.
+	Direction string
+	// Nulls is "first" or "last", or empty for the default of database.
+	Nulls string
+	// Raw is the raw expression used instead of Column.
+	Raw string
+	// Random orders the rows randomly instead of by Column.
+	Random bool
+}
+
+// Group is an item of group by.
+type Group struct {
+	Column string
+	// Raw is the raw expression used instead of Column.
+	Raw string
+}
+
+// Union is a compound select combined with the statement.
+type Union struct {
+	// Type is "union", "union all", "intersect" or "except".
+	Type string
+	Sub  *SQLComponent
+}
+
+// Cte is a common table expression.
+type Cte struct {
+	Name string
+	// Columns are the optional column names of the expression.
+	Columns []string
+	// Recursive marks the expression which refers to itself.
+	Recursive bool
+	Sub       *SQLComponent
 }
 
 // RawUpdate contains the expression and arguments.
@@ -115,143 +264,546 @@ func (sql *SQLComponent) getOffset() string {
 	return " offset " + sql.Offset + " "
 }
 
-func (sql *SQLComponent) getOrderBy() string {
-	if sql.Order == "" {
+// useTop reports whether the limit is rendered by "top (n)" if the dialect
+// uses offsetFetch, which is only for the limit without offset of a
+// non-compound select.
+func (sql *SQLComponent) useTop() bool {
+	return sql.Limit != "" && sql.Offset == "" && len(sql.Unions) == 0
+}
+
+func (sql *SQLComponent) getLimitOffset(c commonDialect) string {
+	if !c.offsetFetch {
+		return sql.getLimit() + sql.getOffset()
+	}
+	if sql.Limit == "" && sql.Offset == "" || sql.useTop() {
+		return ""
+	}
+
+	paging := ""
+	if len(sql.Orders) == 0 {
+		// offset requires order by, which keeps the order of database here
+		paging = " order by (select null)"
+	}
+	offset := sql.Offset
+	if offset == "" {
+		offset = "0"
+	}
+	paging += " offset " + offset + " rows"
+	if sql.Limit != "" {
+		paging += " fetch next " + sql.Limit + " rows only"
+	}
+	return paging + " "
+}
+
+func (sql *SQLComponent) getOrderBy(c commonDialect) string {
+	if len(sql.Orders) == 0 {
+		return ""
+	}
+	orders := make([]string, 0, len(sql.Orders))
+	for _, order := range sql.Orders {
+		switch {
+		case order.Raw != "":
+			orders = append(orders, order.Raw)
+		case order.Random:
+			if c.random == "" {
+				sql.setError(fmt.Errorf("%w: random order", ErrUnsupported))
+			}
+			orders = append(orders, c.random)
+		default:
+			column := c.wrapField(order.Column)
+			if order.Nulls != "" && !c.nullsOrder {
+				// emulate by ordering the null flag first
+				if order.Nulls == "first" {
+					orders = append(orders, "case when "+column+" is null then 0 else 1 end")
+				} else {
+					orders = append(orders, "case when "+column+" is null then 1 else 0 end")
+				}
+			}
+			if order.Direction != "" {
+				column += " " + order.Direction
+			}
+			if order.Nulls != "" && c.nullsOrder {
+				column += " nulls " + order.Nulls
+			}
+			orders = append(orders, column)
+		}
+	}
+	return " order by " + strings.Join(orders, ", ") + " "
+}
+
+func (sql *SQLComponent) getGroupBy(c commonDialect) string {
+	if len(sql.Groups) == 0 {
 		return ""
 	}
-	return " order by " + sql.Order + " "
+	groups := make([]string, len(sql.Groups))
+	for k, group := range sql.Groups {
+		if group.Raw != "" {
+			groups[k] = group.Raw
+		} else {
+			groups[k] = c.wrapField(group.Column)
+		}
+	}
+	return " group by " + strings.Join(groups, ", ") + " "
 }
 
-func (sql *SQLComponent) getGroupBy() string {
-	if sql.Group == "" {
+func (sql *SQLComponent) getHavings(c commonDialect) string {
+	conditions := sql.getConditions(c, sql.Havings)
+	if conditions == "" {
 		return ""
 	}
-	return " group by " + sql.Group + " "
+	return " having " + conditions + " "
 }
 
-func (sql *SQLComponent) getJoins(delimiter string) string {
-	if len(sql.Leftjoins) == 0 {
+func (sql *SQLComponent) getJoins(c commonDialect) string {
+	if len(sql.Joins) == 0 {
 		return ""
 	}
 	joins := ""
-	for _, join := range sql.Leftjoins {
-		joins += " left join " + wrap(delimiter, join.Table) + " on " + join.FieldA + " " + join.Operation + " " + join.FieldB + " "
+	for _, join := range sql.Joins {
+		typ := join.Type
+		if typ == "" {
+			typ = "left"
+		}
+		if typ == "full" && !c.fullJoin {
+			sql.setError(fmt.Errorf("%w: full join", ErrUnsupported))
+		}
+
+		if join.Sub != nil {
+			joins += " " + typ + " join (" + sql.getSubquery(c, join.Sub) + ") as " + c.wrap(join.Table)
+		} else {
+			joins += " " + typ + " join " + c.QuoteIdent(join.Table)
+		}
+
+		switch {
+		case len(join.Conditions) > 0:
+			joins += " on " + sql.getConditions(c, join.Conditions) + " "
+		case join.FieldA != "":
+			joins += " on " + c.wrapField(join.FieldA) + " " + join.Operation + " " + c.wrapField(join.FieldB) + " "
+		}
 	}
 	return joins
 }
 
-func (sql *SQLComponent) getFields(delimiter string) string {
+func (sql *SQLComponent) getFields(c commonDialect) string {
 	if len(sql.Fields) == 0 {
 		return "*"
 	}
-	fields := ""
-	if len(sql.Leftjoins) == 0 {
-		for k, field := range sql.Fields {
-			if sql.Functions[k] != "" {
-				fields += sql.Functions[k] + "(" + wrap(delimiter, field) + "),"
-			} else {
-				fields += wrap(delimiter, field) + ","
-			}
+	fields := make([]string, len(sql.Fields))
+	for k, field := range sql.Fields {
+		switch {
+		case field.Sub != nil:
+			fields[k] = "(" + sql.getSubquery(c, field.Sub) + ")"
+		case field.Raw != "":
+			fields[k] = sql.bind(c, field.Raw, field.Args)
+		case field.Function != "":
+			fields[k] = field.Function + "(" + c.wrapArg(field.Name) + ")"
+		default:
+			fields[k] = c.wrapField(field.Name)
 		}
-	} else {
-		for _, field := range sql.Fields {
-			arr := strings.Split(field, ".")
-			if len(arr) > 1 {
-				fields += arr[0] + "." + wrap(delimiter, arr[1]) + ","
-			} else {
-				fields += wrap(delimiter, field) + ","
-			}
+		if field.Alias != "" {
+			fields[k] += " as " + c.wrap(field.Alias)
 		}
 	}
-	return fields[:len(fields)-1]
+	return strings.Join(fields, ",")
 }
 
-func wrap(delimiter, field string) string {
-	if field == "*" {
-		return "*"
+func (sql *SQLComponent) getUnions(c commonDialect) string {
+	unions := ""
+	for _, union := range sql.Unions {
+		if (union.Type == "intersect" || union.Type == "except") && !c.intersectExcept {
+			sql.setError(fmt.Errorf("%w: %s", ErrUnsupported, union.Type))
+		}
+		member := sql.getSubquery(c, union.Sub)
+		if len(union.Sub.Orders) > 0 || union.Sub.Limit != "" || union.Sub.Offset != "" {
+			if !c.compoundParens {
+				sql.setError(fmt.Errorf("%w: order or limit of %s member", ErrUnsupported, union.Type))
+			}
+			member = "(" + member + ")"
+		}
+		unions += " " + union.Type + " " + member
 	}
-	return delimiter + field + delimiter
+	return unions
 }
 
-func (sql *SQLComponent) getWheres(delimiter string) string {
-	if len(sql.Wheres) == 0 {
-		if sql.WhereRaws != "" {
-			return " where " + sql.WhereRaws
-		}
+// getCtes renders the with clause, whose arguments are appended before the
+// ones of statement.
+func (sql *SQLComponent) getCtes(c commonDialect) string {
+	if len(sql.Ctes) == 0 {
 		return ""
 	}
-	wheres := " where "
-	var arr []string
-	for _, where := range sql.Wheres {
-		arr = strings.Split(where.Field, ".")
-		if len(arr) > 1 {
-			wheres += arr[0] + "." + wrap(delimiter, arr[1]) + " " + where.Operation + " " + where.Qmark + " and "
-		} else {
-			wheres += wrap(delimiter, where.Field) + " " + where.Operation + " " + where.Qmark + " and "
+	var (
+		ctes      = make([]string, len(sql.Ctes))
+		recursive = false
+	)
+	for k, cte := range sql.Ctes {
+		ctes[k] = c.wrap(cte.Name)
+		if len(cte.Columns) > 0 {
+			ctes[k] += " (" + c.wrapColumns(cte.Columns, "") + ")"
 		}
+		ctes[k] += " as (" + sql.getSubquery(c, cte.Sub) + ")"
+		recursive = recursive || cte.Recursive
+	}
+	if recursive && c.recursiveKeyword {
+		return "with recursive " + strings.Join(ctes, ", ") + " "
 	}
+	return "with " + strings.Join(ctes, ", ") + " "
+}
 
-	if sql.WhereRaws != "" {
-		return wheres + sql.WhereRaws
+func (sql *SQLComponent) getTable(c commonDialect) string {
+	if sql.From != nil {
+		return "(" + sql.getSubquery(c, sql.From) + ") as " + c.wrap(sql.TableName)
 	}
-	return wheres[:len(wheres)-5]
+	return c.QuoteIdent(sql.TableName)
 }
 
-func (sql *SQLComponent) prepareUpdate(delimiter string) {
-	fields := ""
-	args := make([]interface{}, 0)
+// QuoteIdent quotes the identifier, which can be qualified as
+// "schema.table.column", aliased as "users as u" or "users u", or "*". The
+// delimiters in the identifier are escaped, and the quoted parts are left as
+// they are. An expression with parentheses is left as it is except the alias
+// of it, e.g. "count(distinct id) as users".
+func (c commonDialect) QuoteIdent(ident string) string {
+	ident = strings.TrimSpace(ident)
+	if strings.ContainsAny(ident, "()") {
+		if expr, alias, ok := splitAlias(ident); ok {
+			return expr + " as " + c.wrap(alias)
+		}
+		return ident
+	}
 
-	if len(sql.Values) != 0 {
+	parts := strings.Fields(ident)
+	switch {
+	case len(parts) > 1 && identKeywords[strings.ToLower(parts[0])]:
+		return strings.ToLower(parts[0]) + " " + c.QuoteIdent(strings.Join(parts[1:], " "))
+	case len(parts) == 3 && strings.EqualFold(parts[1], "as"):
+		return c.QuoteIdent(parts[0]) + " as " + c.wrap(parts[2])
+	case len(parts) == 2:
+		return c.QuoteIdent(parts[0]) + " as " + c.wrap(parts[1])
+	case len(parts) != 1:
+		return c.wrap(strings.TrimSpace(ident))
+	}
 
-		for key, value := range sql.Values {
-			fields += wrap(delimiter, key) + " = ?, "
-			args = append(args, value)
+	names := c.splitIdent(parts[0])
+	for i, name := range names {
+		names[i] = c.wrap(name)
+	}
+	return strings.Join(names, ".")
+}
+
+// identKeywords are the keywords which can precede a column, e.g. "distinct
+// user_id", instead of being aliased by it.
+var identKeywords = map[string]bool{"distinct": true, "all": true}
+
+// splitAlias splits the alias given by the last "as" out of the parentheses
+// and quotes of the expression.
+func splitAlias(expr string) (string, string, bool) {
+	depth, as := 0, -1
+	for i := 0; i < len(expr); i++ {
+		if end := skipQuoted(expr, i); end > i {
+			i = end - 1
+			continue
+		}
+		switch ch := expr[i]; {
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && (ch == ' ' || ch == '\t' || ch == '\n') && len(expr) > i+3 &&
+			strings.EqualFold(expr[i+1:i+3], "as") && (expr[i+3] == ' ' || expr[i+3] == '\t' || expr[i+3] == '\n'):
+			as = i
 		}
+	}
+	if as < 0 {
+		return "", "", false
+	}
+	alias := strings.TrimSpace(expr[as+4:])
+	if alias == "" || strings.ContainsAny(alias, " \t\n()") {
+		return "", "", false
+	}
+	return strings.TrimSpace(expr[:as]), alias, true
+}
 
-		if len(sql.UpdateRaws) == 0 {
-			fields = fields[:len(fields)-2]
-		} else {
-			for i := 0; i < len(sql.UpdateRaws); i++ {
-				if i == len(sql.UpdateRaws)-1 {
-					fields += sql.UpdateRaws[i].Expression + " "
-				} else {
-					fields += sql.UpdateRaws[i].Expression + ","
-				}
-				args = append(args, sql.UpdateRaws[i].Args...)
+// splitIdent splits the qualified identifier by the dots out of the quoted parts.
+func (c commonDialect) splitIdent(ident string) []string {
+	var (
+		names  = make([]string, 0, 1)
+		start  = 0
+		quoted = false
+	)
+	for i := 0; i < len(ident); i++ {
+		switch {
+		case !quoted && strings.HasPrefix(ident[i:], c.delimiter):
+			quoted = true
+		case quoted && strings.HasPrefix(ident[i:], c.closing()):
+			if strings.HasPrefix(ident[i+1:], c.closing()) {
+				// escaped delimiter
+				i++
+			} else {
+				quoted = false
 			}
+		case !quoted && ident[i] == '.':
+			names = append(names, ident[start:i])
+			start = i + 1
 		}
+	}
+	return append(names, ident[start:])
+}
 
-		sql.Args = append(args, sql.Args...)
-	} else {
-		if len(sql.UpdateRaws) == 0 {
-			panic("prepareUpdate: wrong parameter")
-		} else {
-			for i := 0; i < len(sql.UpdateRaws); i++ {
-				if i == len(sql.UpdateRaws)-1 {
-					fields += sql.UpdateRaws[i].Expression + " "
-				} else {
-					fields += sql.UpdateRaws[i].Expression + ","
-				}
-				args = append(args, sql.UpdateRaws[i].Args...)
+// wrap quotes a single identifier and escapes the delimiters in it.
+func (c commonDialect) wrap(name string) string {
+	if name == "*" {
+		return "*"
+	}
+	closing := c.closing()
+	if len(name) > 1 && strings.HasPrefix(name, c.delimiter) && strings.HasSuffix(name, closing) {
+		// quoted already
+		return name
+	}
+	return c.delimiter + strings.Replace(name, closing, closing+closing, -1) + closing
+}
+
+func (c commonDialect) closing() string {
+	if c.closeDelimiter != "" {
+		return c.closeDelimiter
+	}
+	return c.delimiter
+}
+
+// wrapArg quotes the column which is the argument of a function, e.g. "id" of
+// "count(id)", or "distinct id" of "count(distinct id)". The expressions are
+// left as they are, which are not aliased.
+func (c commonDialect) wrapArg(arg string) string {
+	parts := strings.Fields(arg)
+	switch {
+	case len(parts) > 1 && identKeywords[strings.ToLower(parts[0])]:
+		return strings.ToLower(parts[0]) + " " + c.wrapArg(strings.Join(parts[1:], " "))
+	case len(parts) == 1 && !strings.ContainsAny(arg, "()"):
+		return c.QuoteIdent(parts[0])
+	default:
+		return arg
+	}
+}
+
+// wrapField quotes the column of field, or leaves it as it is if field is an
+// expression, e.g. "count(id)".
+func (c commonDialect) wrapField(field string) string {
+	if strings.Contains(field, "(") {
+		return field
+	}
+	return c.QuoteIdent(field)
+}
+
+func (sql *SQLComponent) getWheres(c commonDialect) string {
+	conditions := sql.getConditions(c, sql.Wheres)
+	if conditions == "" {
+		return ""
+	}
+	return " where " + conditions
+}
+
+// getConditions joins the conditions by their connectors and appends the
+// arguments of them in order.
+func (sql *SQLComponent) getConditions(c commonDialect, wheres []Where) string {
+	conditions := ""
+	for _, where := range wheres {
+		var condition string
+		switch {
+		case where.Nested != nil:
+			condition = sql.getConditions(c, where.Nested)
+			if condition == "" {
+				continue
+			}
+			condition = "(" + condition + ")"
+		case where.Raw != "":
+			condition = sql.bind(c, where.Raw, where.Args)
+			if len(wheres) > 1 {
+				condition = "(" + condition + ")"
 			}
+		default:
+			condition = sql.getCondition(c, where)
 		}
-		sql.Args = append(args, sql.Args...)
+
+		if conditions == "" {
+			conditions = condition
+		} else if where.Or {
+			conditions += " or " + condition
+		} else {
+			conditions += " and " + condition
+		}
+	}
+	return conditions
+}
+
+func (sql *SQLComponent) getCondition(c commonDialect, where Where) string {
+	condition := ""
+	if where.Field != "" {
+		condition = c.wrapField(where.Field)
+		if where.Date {
+			condition = c.date(condition)
+		}
+		condition += " "
+	}
+	condition += where.Operation
+
+	switch {
+	case where.Sub != nil:
+		condition += " (" + sql.getSubquery(c, where.Sub) + ")"
+	case where.Column != "":
+		condition += " " + c.wrapField(where.Column)
+	case where.Qmark != "":
+		condition += " " + sql.bind(c, where.Qmark, where.Args)
+	}
+
+	if where.Escape && c.likeEscape != "" {
+		condition += " escape '" + c.likeEscape + "'"
+	}
+	return condition
+}
+
+// getSubquery renders the select statement of sub with the same dialect, and
+// appends the arguments of it after the ones rendered before.
+func (sql *SQLComponent) getSubquery(c commonDialect, sub *SQLComponent) string {
+	sub.Args = sql.Args
+	sub.err = nil
+	statement := c.selectStatement(sub)
+	sql.Args = sub.Args
+	if sub.err != nil {
+		sql.setError(sub.err)
+	}
+	return statement
+}
+
+// setError records the first error occurred in rendering.
+func (sql *SQLComponent) setError(err error) {
+	if sql.err == nil {
+		sql.err = err
+	}
+}
+
+func (sql *SQLComponent) prepareUpdate(c commonDialect) error {
+	if len(sql.Values) == 0 && len(sql.UpdateRaws) == 0 {
+		return ErrEmptyUpdate
 	}
 
-	sql.Statement = "update " + sql.TableName + " set " + fields + sql.getWheres(delimiter)
+	sql.Args = make([]interface{}, 0)
+
+	sets := make([]string, 0, len(sql.Values)+len(sql.UpdateRaws))
+	for _, key := range sortedKeys(sql.Values) {
+		sets = append(sets, c.wrap(key)+" = "+sql.bindValue(c, sql.Values[key]))
+	}
+	for _, raw := range sql.UpdateRaws {
+		sets = append(sets, sql.bind(c, raw.Expression, raw.Args))
+	}
+
+	sql.Statement = "update " + c.QuoteIdent(sql.TableName) + " set " + strings.Join(sets, ", ") + sql.getWheres(c)
+	return nil
 }
 
-func (sql *SQLComponent) prepareInsert(delimiter string) {
+func (sql *SQLComponent) prepareInsert(c commonDialect) error {
+	if len(sql.Values) == 0 {
+		return ErrEmptyInsert
+	}
+
+	sql.Args = make([]interface{}, 0)
+
 	fields := " ("
 	quesMark := "("
 
-	for key, value := range sql.Values {
-		fields += wrap(delimiter, key) + ","
-		quesMark += "?,"
-		sql.Args = append(sql.Args, value)
+	for _, key := range sortedKeys(sql.Values) {
+		fields += c.wrap(key) + ","
+		quesMark += sql.bindValue(c, sql.Values[key]) + ","
 	}
 	fields = fields[:len(fields)-1] + ")"
 	quesMark = quesMark[:len(quesMark)-1] + ")"
 
-	sql.Statement = "insert into " + sql.TableName + fields + " values " + quesMark
+	output := ""
+	if sql.Returning != "" && c.returning == "output" {
+		output = " output inserted." + c.wrap(sql.Returning)
+	}
+
+	sql.Statement = "insert into " + c.QuoteIdent(sql.TableName) + fields + output + " values " + quesMark
+	return nil
+}
+
+func (sql *SQLComponent) prepareInsertBatch(c commonDialect) error {
+	if len(sql.Batch) == 0 || len(sql.Batch[0]) == 0 {
+		return ErrEmptyInsert
+	}
+
+	keys := sortedKeys(sql.Batch[0])
+
+	fields := " ("
+	for _, key := range keys {
+		fields += c.wrap(key) + ","
+	}
+	fields = fields[:len(fields)-1] + ")"
+
+	sql.Args = make([]interface{}, 0, len(keys)*len(sql.Batch))
+	values := make([]string, len(sql.Batch))
+	for i, row := range sql.Batch {
+		if len(row) != len(keys) {
+			return ErrBatchColumns
+		}
+		marks := make([]string, len(keys))
+		for j, key := range keys {
+			value, ok := row[key]
+			if !ok {
+				return ErrBatchColumns
+			}
+			marks[j] = sql.bindValue(c, value)
+		}
+		values[i] = "(" + strings.Join(marks, ",") + ")"
+	}
+
+	sql.Statement = "insert into " + c.QuoteIdent(sql.TableName) + fields + " values " + strings.Join(values, ",")
+	return nil
+}
+
+// upsertColumns return the columns to update on conflict, which are all the
+// inserted columns except the conflict ones if UpdateColumns is empty.
+func (sql *SQLComponent) upsertColumns() []string {
+	if len(sql.UpdateColumns) > 0 {
+		return sql.UpdateColumns
+	}
+	columns := make([]string, 0, len(sql.Values))
+	for _, key := range sortedKeys(sql.Values) {
+		conflict := false
+		for _, col := range sql.ConflictColumns {
+			if col == key {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			columns = append(columns, key)
+		}
+	}
+	return columns
+}
+
+func (c commonDialect) wrapColumns(columns []string, prefix string) string {
+	list := make([]string, len(columns))
+	for i, col := range columns {
+		list[i] = prefix + c.wrap(col)
+	}
+	return strings.Join(list, ",")
+}
+
+// EscapeLike escapes the wildcard characters of like operation in value, so
+// that it matches literally in the pattern of SQL.WhereLike.
+//
+//	sql.WhereLike("name", "%"+dialect.EscapeLike(keyword)+"%")
+func EscapeLike(value string) string {
+	return likeReplacer.Replace(value)
+}
+
+var likeReplacer = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`)
+
+// sortedKeys return the keys of the map in a stable order.
+func sortedKeys(values H) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
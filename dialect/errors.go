@@ -0,0 +1,24 @@
+package dialect
+
+import "errors"
+
+var (
+	// ErrEmptyUpdate is returned when there is neither value nor raw expression to update.
+	ErrEmptyUpdate = errors.New("empty update values")
+
+	// ErrEmptyInsert is returned when there is no value to insert.
+	ErrEmptyInsert = errors.New("empty insert values")
+
+	// ErrBatchColumns is returned when the rows of batch insert have different columns.
+	ErrBatchColumns = errors.New("different columns in batch insert rows")
+
+	// ErrTooManyColumns is returned when a row has more columns than the placeholders
+	// allowed in one statement.
+	ErrTooManyColumns = errors.New("too many columns in one statement")
+
+	// ErrEmptyConflict is returned when the conflict columns of upsert are required but empty.
+	ErrEmptyConflict = errors.New("empty conflict columns of upsert")
+
+	// ErrUnsupported is returned when the statement is not supported by the dialect.
+	ErrUnsupported = errors.New("unsupported by the dialect")
+)
@@ -0,0 +1,120 @@
+package dialect
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interpolate return the statement with the placeholders replaced by the
+// literals of args in the syntax of d. It is for logging and debugging only,
+// and the result must not be executed, use the placeholders instead.
+func Interpolate(d Dialect, statement string, args ...interface{}) string {
+	var (
+		b          = strings.Builder{}
+		n          = 0
+		sequential = d.Placeholder(1) == "?"
+		prefix     = strings.TrimSuffix(d.Placeholder(1), "1")
+	)
+	for i := 0; i < len(statement); i++ {
+		if end := skipQuoted(statement, i); end > i {
+			b.WriteString(statement[i:end])
+			i = end - 1
+			continue
+		}
+
+		if strings.HasPrefix(statement[i:], "??") {
+			// the escaped "?" which is not a placeholder
+			b.WriteByte('?')
+			i++
+			continue
+		}
+
+		index := -1
+		if sequential && statement[i] == '?' {
+			index = n
+			n++
+		} else if !sequential && strings.HasPrefix(statement[i:], prefix) {
+			j := i + len(prefix)
+			for j < len(statement) && '0' <= statement[j] && statement[j] <= '9' {
+				j++
+			}
+			if j > i+len(prefix) {
+				index, _ = strconv.Atoi(statement[i+len(prefix) : j])
+				index--
+				i = j - 1
+			}
+		}
+
+		switch {
+		case index < 0:
+			b.WriteByte(statement[i])
+		case index < len(args):
+			b.WriteString(literal(d, args[index]))
+		default:
+			// no argument, left as it is
+			b.WriteString(d.Placeholder(index + 1))
+		}
+	}
+	return b.String()
+}
+
+// literal return the literal of value in the syntax of d.
+func literal(d Dialect, value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return "'<" + err.Error() + ">'"
+		}
+		value = v
+	}
+
+	_, isMysql := d.(mysql)
+	_, isPostgresql := d.(postgresql)
+	_, isMssql := d.(mssql)
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		switch {
+		case isPostgresql && v:
+			return "TRUE"
+		case isPostgresql:
+			return "FALSE"
+		case v:
+			return "1"
+		default:
+			return "0"
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	case []byte:
+		switch {
+		case isPostgresql:
+			return `'\x` + hex.EncodeToString(v) + "'"
+		case isMssql:
+			return "0x" + hex.EncodeToString(v)
+		default:
+			return "X'" + hex.EncodeToString(v) + "'"
+		}
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'"
+	case string:
+		return quoteString(v, isMysql)
+	default:
+		return quoteString(fmt.Sprint(v), isMysql)
+	}
+}
+
+// quoteString quotes s as a string literal, escaping the backslashes too if
+// they are escape characters, as in mysql.
+func quoteString(s string, backslash bool) string {
+	if backslash {
+		s = strings.Replace(s, `\`, `\\`, -1)
+	}
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,37 @@
+package dialect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		driver    string
+		statement string
+		args      []interface{}
+		want      string
+	}{
+		{"mysql", "select * from t where a = ? and b = ?", []interface{}{1, "x"}, "select * from t where a = 1 and b = 'x'"},
+		{"mysql", "a ?? b and c = ?", []interface{}{78}, "a ? b and c = 78"},
+		{"mysql", "a = ? and b = '?'", []interface{}{1}, "a = 1 and b = '?'"},
+		{"mysql", "a = ?", []interface{}{`it's \`}, `a = 'it''s \\'`},
+		{"mysql", "a = ? and b = ?", []interface{}{1}, "a = 1 and b = ?"},
+		{"mysql", "a = ?", []interface{}{nil}, "a = NULL"},
+		{"mysql", "a = ? and b = ?", []interface{}{true, []byte{0xab}}, "a = 1 and b = X'ab'"},
+		{"mysql", "a = ?", []interface{}{at}, "a = '2024-01-02 03:04:05'"},
+		{"postgresql", "a = $1 and b = $2", []interface{}{"x", false}, "a = 'x' and b = FALSE"},
+		{"postgresql", "data ?? 'k' and a = $1", []interface{}{1}, "data ? 'k' and a = 1"},
+		{"postgresql", "a = $2 and b = $1", []interface{}{1, 2}, "a = 2 and b = 1"},
+		{"postgresql", "a = $1", []interface{}{[]byte{0x01}}, `a = '\x01'`},
+		{"postgresql", "a = $1 and b = '$1'", []interface{}{`\`}, `a = '\' and b = '$1'`},
+		{"mssql", "a = @p1 and b = @p2", []interface{}{[]byte{0xff}, 1.5}, "a = 0xff and b = 1.5"},
+		{"mssql", "a = @p3", []interface{}{1}, "a = @p3"},
+	}
+	for _, tt := range tests {
+		if got := Interpolate(GetDialectByDriver(tt.driver), tt.statement, tt.args...); got != tt.want {
+			t.Errorf("%s Interpolate(%q, %v) = %q, want %q", tt.driver, tt.statement, tt.args, got, tt.want)
+		}
+	}
+}
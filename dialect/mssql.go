@@ -1,5 +1,7 @@
 package dialect
 
+import "strings"
+
 type mssql struct {
 	commonDialect
 }
@@ -7,3 +9,40 @@ type mssql struct {
 func (mssql) GetName() string {
 	return "mssql"
 }
+
+func (m mssql) Upsert(comp *SQLComponent) (string, error) {
+	if len(comp.ConflictColumns) == 0 {
+		return "", ErrEmptyConflict
+	}
+	if len(comp.Values) == 0 {
+		return "", ErrEmptyInsert
+	}
+
+	keys := sortedKeys(comp.Values)
+	comp.Args = make([]interface{}, 0, len(keys))
+	marks := make([]string, len(keys))
+	for i, key := range keys {
+		marks[i] = comp.bindValue(m.commonDialect, comp.Values[key])
+	}
+
+	on := make([]string, len(comp.ConflictColumns))
+	for i, col := range comp.ConflictColumns {
+		on[i] = "target." + m.wrap(col) + " = source." + m.wrap(col)
+	}
+
+	comp.Statement = "merge into " + m.QuoteIdent(comp.TableName) + " with (holdlock) as target using (values (" +
+		strings.Join(marks, ",") + ")) as source (" + m.wrapColumns(keys, "") +
+		") on " + strings.Join(on, " and ")
+
+	if columns := comp.upsertColumns(); len(columns) > 0 {
+		sets := make([]string, len(columns))
+		for i, col := range columns {
+			sets[i] = m.wrap(col) + " = source." + m.wrap(col)
+		}
+		comp.Statement += " when matched then update set " + strings.Join(sets, ", ")
+	}
+
+	comp.Statement += " when not matched then insert (" + m.wrapColumns(keys, "") +
+		") values (" + m.wrapColumns(keys, "source.") + ");"
+	return comp.Statement, nil
+}
@@ -0,0 +1,216 @@
+package dialect
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+// render renders comp by the statement method of d, and returns the statement
+// followed by the arguments.
+func render(d Dialect, method string, comp *SQLComponent) (string, error) {
+	var (
+		statement string
+		err       error
+	)
+	switch method {
+	case "select":
+		statement, err = d.Select(comp)
+	case "insert":
+		statement, err = d.Insert(comp)
+	case "insertBatch":
+		statement, err = d.InsertBatch(comp)
+	case "update":
+		statement, err = d.Update(comp)
+	case "delete":
+		statement, err = d.Delete(comp)
+	case "upsert":
+		statement, err = d.Upsert(comp)
+	default:
+		return "", fmt.Errorf("unknown method %q", method)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s\n-- args: %v\n", statement, comp.Args), nil
+}
+
+// assertGolden compares got with the golden file of name, which is written
+// instead if the -update flag is set.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestMssqlGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		comp   SQLComponent
+	}{
+		{
+			name:   "top",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Fields:    []Field{{Name: "id"}, {Name: "name"}},
+				Wheres:    []Where{{Field: "age", Operation: ">", Qmark: "?", Args: []interface{}{18}}},
+				Orders:    []Order{{Column: "id", Direction: "desc"}},
+				Limit:     "10",
+			},
+		},
+		{
+			name:   "offset_fetch",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Orders:    []Order{{Column: "id"}},
+				Limit:     "10",
+				Offset:    "20",
+			},
+		},
+		{
+			name:   "offset_fetch_unordered",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Limit:     "10",
+				Offset:    "20",
+			},
+		},
+		{
+			name:   "offset_fetch_union_unordered",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Wheres:    []Where{{Field: "age", Operation: ">", Qmark: "?", Args: []interface{}{18}}},
+				Unions: []Union{{Type: "union", Sub: &SQLComponent{
+					TableName: "admins",
+					Wheres:    []Where{{Field: "age", Operation: ">", Qmark: "?", Args: []interface{}{30}}},
+				}}},
+				Limit: "5",
+			},
+		},
+		{
+			name:   "offset_fetch_union_ordered",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Unions:    []Union{{Type: "union", Sub: &SQLComponent{TableName: "admins"}}},
+				Orders:    []Order{{Column: "id"}},
+				Limit:     "5",
+			},
+		},
+		{
+			name:   "offset_only",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Orders:    []Order{{Column: "id"}},
+				Offset:    "5",
+			},
+		},
+		{
+			name:   "bracket_quoting",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "dbo.users as u",
+				Fields:    []Field{{Name: "u.id"}, {Name: "odd]name"}, {Name: "u.*"}},
+				Joins:     []Join{{Type: "left", Table: "dbo.roles r", FieldA: "r.id", Operation: "=", FieldB: "u.role_id"}},
+			},
+		},
+		{
+			name:   "placeholders",
+			method: "select",
+			comp: SQLComponent{
+				TableName: "users",
+				Wheres: []Where{
+					{Field: "name", Operation: "=", Qmark: "?", Args: []interface{}{"a"}},
+					{Field: "id", Operation: "in", Qmark: "(?,?,?)", Args: []interface{}{1, 2, 3}},
+					{Raw: "age between ? and ?", Args: []interface{}{18, 30}, Or: true},
+				},
+				Limit: "1",
+			},
+		},
+		{
+			name:   "insert",
+			method: "insert",
+			comp: SQLComponent{
+				TableName: "users",
+				Values:    H{"name": "a", "age": 18},
+			},
+		},
+		{
+			name:   "insert_output",
+			method: "insert",
+			comp: SQLComponent{
+				TableName: "users",
+				Values:    H{"name": "a"},
+				Returning: "id",
+			},
+		},
+		{
+			name:   "insert_batch",
+			method: "insertBatch",
+			comp: SQLComponent{
+				TableName: "users",
+				Batch:     []H{{"name": "a", "age": 1}, {"name": "b", "age": 2}},
+			},
+		},
+		{
+			name:   "update",
+			method: "update",
+			comp: SQLComponent{
+				TableName: "users",
+				Values:    H{"name": "b"},
+				Wheres:    []Where{{Field: "id", Operation: "=", Qmark: "?", Args: []interface{}{1}}},
+			},
+		},
+		{
+			name:   "delete",
+			method: "delete",
+			comp: SQLComponent{
+				TableName: "users",
+				Wheres:    []Where{{Field: "id", Operation: "=", Qmark: "?", Args: []interface{}{1}}},
+			},
+		},
+		{
+			name:   "upsert",
+			method: "upsert",
+			comp: SQLComponent{
+				TableName:       "users",
+				Values:          H{"id": 1, "name": "a"},
+				ConflictColumns: []string{"id"},
+			},
+		},
+	}
+
+	d := GetDialectByDriver("mssql")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := tt.comp
+			got, err := render(d, tt.method, &comp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, filepath.Join("mssql", tt.name), got)
+		})
+	}
+}
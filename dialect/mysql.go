@@ -1,5 +1,7 @@
 package dialect
 
+import "strings"
+
 type mysql struct {
 	commonDialect
 }
@@ -15,3 +17,25 @@ func (mysql) ShowColumns(table string) string {
 func (mysql) ShowTables() string {
 	return "show tables"
 }
+
+func (m mysql) Upsert(comp *SQLComponent) (string, error) {
+	if err := comp.prepareInsert(m.commonDialect); err != nil {
+		return "", err
+	}
+
+	columns := comp.upsertColumns()
+	if len(columns) == 0 {
+		// update a column to itself, so that the duplicate row is left as it is
+		columns = sortedKeys(comp.Values)[:1]
+		col := m.wrap(columns[0])
+		comp.Statement += " on duplicate key update " + col + " = " + col
+		return comp.Statement, nil
+	}
+
+	sets := make([]string, len(columns))
+	for i, col := range columns {
+		sets[i] = m.wrap(col) + " = values(" + m.wrap(col) + ")"
+	}
+	comp.Statement += " on duplicate key update " + strings.Join(sets, ", ")
+	return comp.Statement, nil
+}
@@ -11,3 +11,7 @@ func (postgresql) GetName() string {
 func (postgresql) ShowTables() string {
 	return "SELECT tablename FROM pg_catalog.pg_tables WHERE schemaname != 'pg_catalog' AND schemaname != 'information_schema';"
 }
+
+func (p postgresql) Upsert(comp *SQLComponent) (string, error) {
+	return p.upsertOnConflict(comp)
+}
@@ -0,0 +1,57 @@
+package dialect
+
+import "testing"
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		driver string
+		ident  string
+		want   string
+	}{
+		{"mysql", "users", "`users`"},
+		{"mysql", "db.users.id", "`db`.`users`.`id`"},
+		{"mysql", "users as u", "`users` as `u`"},
+		{"mysql", "users AS u", "`users` as `u`"},
+		{"mysql", "users u", "`users` as `u`"},
+		{"mysql", "u.*", "`u`.*"},
+		{"mysql", "*", "*"},
+		{"mysql", "odd`name", "`odd``name`"},
+		{"mysql", "`quoted`.id", "`quoted`.`id`"},
+		{"mysql", "distinct user_id", "distinct `user_id`"},
+		{"mysql", "count(distinct user_id)", "count(distinct user_id)"},
+		{"mysql", "count(distinct user_id) as users", "count(distinct user_id) as `users`"},
+		{"mysql", "coalesce(a, 'x as y') total", "coalesce(a, 'x as y') total"},
+		{"mysql", "cast(a as char) as b", "cast(a as char) as `b`"},
+		{"postgresql", "public.users u", `"public"."users" as "u"`},
+		{"mssql", "dbo.users", "[dbo].[users]"},
+		{"mssql", "odd]name", "[odd]]name]"},
+	}
+	for _, tt := range tests {
+		if got := GetDialectByDriver(tt.driver).QuoteIdent(tt.ident); got != tt.want {
+			t.Errorf("%s QuoteIdent(%q) = %q, want %q", tt.driver, tt.ident, got, tt.want)
+		}
+	}
+}
+
+func TestSelectFunctionFields(t *testing.T) {
+	tests := []struct {
+		field Field
+		want  string
+	}{
+		{Field{Function: "count", Name: "*"}, "select count(*) from `t`"},
+		{Field{Function: "count", Name: "distinct user_id"}, "select count(distinct `user_id`) from `t`"},
+		{Field{Function: "sum", Name: "t.amount", Alias: "total"}, "select sum(`t`.`amount`) as `total` from `t`"},
+		{Field{Function: "max", Name: "a + b"}, "select max(a + b) from `t`"},
+		{Field{Name: "count(distinct user_id)"}, "select count(distinct user_id) from `t`"},
+	}
+	for _, tt := range tests {
+		comp := &SQLComponent{TableName: "t", Fields: []Field{tt.field}}
+		got, err := GetDialectByDriver("mysql").Select(comp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("Select(%+v) = %q, want %q", tt.field, got, tt.want)
+		}
+	}
+}
@@ -15,3 +15,7 @@ func (sqlite) ShowColumns(table string) string {
 func (sqlite) ShowTables() string {
 	return "SELECT name as tablename FROM sqlite_master WHERE type ='table'"
 }
+
+func (s sqlite) Upsert(comp *SQLComponent) (string, error) {
+	return s.upsertOnConflict(comp)
+}
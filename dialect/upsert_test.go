@@ -0,0 +1,89 @@
+package dialect
+
+import "testing"
+
+func TestUpsert(t *testing.T) {
+	values := H{"id": 1, "name": "a", "age": 20}
+
+	tests := []struct {
+		name      string
+		driver    string
+		conflict  []string
+		update    []string
+		statement string
+		err       error
+	}{
+		{
+			name:      "mysql update all",
+			driver:    "mysql",
+			conflict:  []string{"id"},
+			statement: "insert into `users` (`age`,`id`,`name`) values (?,?,?) on duplicate key update `age` = values(`age`), `name` = values(`name`)",
+		},
+		{
+			name:      "mysql update columns",
+			driver:    "mysql",
+			update:    []string{"name"},
+			statement: "insert into `users` (`age`,`id`,`name`) values (?,?,?) on duplicate key update `name` = values(`name`)",
+		},
+		{
+			name:      "postgresql update all",
+			driver:    "postgresql",
+			conflict:  []string{"id"},
+			statement: `insert into "users" ("age","id","name") values ($1,$2,$3) on conflict ("id") do update set "age" = excluded."age", "name" = excluded."name"`,
+		},
+		{
+			name:      "postgresql update columns",
+			driver:    "postgresql",
+			conflict:  []string{"id"},
+			update:    []string{"name"},
+			statement: `insert into "users" ("age","id","name") values ($1,$2,$3) on conflict ("id") do update set "name" = excluded."name"`,
+		},
+		{
+			name:      "postgresql all conflict",
+			driver:    "postgresql",
+			conflict:  []string{"age", "id", "name"},
+			statement: `insert into "users" ("age","id","name") values ($1,$2,$3) on conflict ("age","id","name") do nothing`,
+		},
+		{
+			name:   "postgresql empty conflict",
+			driver: "postgresql",
+			err:    ErrEmptyConflict,
+		},
+		{
+			name:      "sqlite update all",
+			driver:    "sqlite",
+			conflict:  []string{"id"},
+			statement: "insert into `users` (`age`,`id`,`name`) values (?,?,?) on conflict (`id`) do update set `age` = excluded.`age`, `name` = excluded.`name`",
+		},
+		{
+			name:   "sqlite empty conflict",
+			driver: "sqlite",
+			err:    ErrEmptyConflict,
+		},
+		{
+			name:   "mssql empty conflict",
+			driver: "mssql",
+			err:    ErrEmptyConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comp := &SQLComponent{
+				TableName:       "users",
+				Values:          values,
+				ConflictColumns: tt.conflict,
+				UpdateColumns:   tt.update,
+			}
+			statement, err := GetDialectByDriver(tt.driver).Upsert(comp)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if statement != tt.statement {
+				t.Errorf("statement:\ngot:  %s\nwant: %s", statement, tt.statement)
+			}
+			if err == nil && len(comp.Args) != len(values) {
+				t.Errorf("got %d args, want %d", len(comp.Args), len(values))
+			}
+		})
+	}
+}
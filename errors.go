@@ -0,0 +1,65 @@
+package connection
+
+import (
+	dbsql "database/sql"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrDriverNotFound is returned when the given driver is not supported.
+	ErrDriverNotFound = errors.New("driver not found")
+
+	// ErrConnectionNotFound is returned when the given connection name is not initialized.
+	ErrConnectionNotFound = errors.New("connection not found")
+
+	// ErrNoRows is returned by the terminal methods when the query returns no rows.
+	// It is the same value as sql.ErrNoRows.
+	ErrNoRows = dbsql.ErrNoRows
+
+	// ErrNoAffectRow is returned when the exec statement affects no rows.
+	ErrNoAffectRow = errors.New("no affect row")
+
+	// ErrEmptyIn is returned when the arguments of in operation is empty.
+	ErrEmptyIn = errors.New("empty arguments of in operation")
+
+	// ErrEmptyOrder is returned when the fields of order by is empty.
+	ErrEmptyOrder = errors.New("empty order by fields")
+
+	// ErrEmptyGroup is returned when the fields of group by is empty.
+	ErrEmptyGroup = errors.New("empty group by fields")
+
+	// ErrUnmappedColumn is returned when a column of the results has no field
+	// in the destination struct.
+	ErrUnmappedColumn = errors.New("unmapped column")
+
+	// ErrZeroPrimaryKey is returned by UpdateStruct when the primary key of the
+	// struct has the zero value.
+	ErrZeroPrimaryKey = errors.New("zero value of primary key")
+
+	// ErrNoPrimaryKey is returned by UpdateStruct when the struct has no
+	// primary key and there is no where condition.
+	ErrNoPrimaryKey = errors.New("no primary key or where condition")
+)
+
+// Errors is a list of errors accumulated by the SQL builder.
+type Errors []error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Is reports whether any of the accumulated errors matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package connection
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chenhg5/go-sql/dialect"
+)
+
+func TestInitDBError(t *testing.T) {
+	conn := GetMysqlDB()
+	cfg := map[string]Database{"default": {Dsn: "%bad dsn"}}
+	_, err := conn.InitDB(cfg)
+	if err == nil {
+		t.Fatal("expect an error of the invalid dsn")
+	}
+	if _, again := conn.InitDB(cfg); again != err {
+		t.Errorf("err of the second InitDB = %v, want %v", again, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn,
+		"create table users (id integer primary key, name text)",
+		"insert into users values (1, 'a')",
+	)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		want error
+	}{
+		{"no driver", func() error {
+			_, err := Table("users").All()
+			return err
+		}, ErrDriverNotFound},
+		{"unknown connection", func() error {
+			_, err := WithDriverAndConnection("missing", conn).Table("users").All()
+			return err
+		}, ErrConnectionNotFound},
+		{"no rows", func() error {
+			_, err := WithDriver(conn).Table("users").Where("id", "=", 2).First()
+			return err
+		}, ErrNoRows},
+		{"no affect row", func() error {
+			_, err := WithDriver(conn).Table("users").Where("id", "=", 2).Update(dialect.H{"name": "b"})
+			return err
+		}, ErrNoAffectRow},
+		{"no affect row of delete", func() error {
+			return WithDriver(conn).Table("users").Where("id", "=", 2).Delete()
+		}, ErrNoAffectRow},
+		{"empty in", func() error {
+			_, err := WithDriver(conn).Table("users").WhereIn("id", []int{}).All()
+			return err
+		}, ErrEmptyIn},
+	}
+	for _, tt := range tests {
+		if err := tt.fn(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	_, err := WithDriver(conn).Table("users").WhereIn("id", []int{}).GroupBy().All()
+	if _, ok := err.(Errors); !ok || !errors.Is(err, ErrEmptyIn) || !errors.Is(err, ErrEmptyGroup) {
+		t.Errorf("err = %#v, want the Errors of ErrEmptyIn and ErrEmptyGroup", err)
+	}
+
+	// the query is not executed once the builder has an error
+	sql := WithDriver(conn).Table("users").WhereIn("id", []int{})
+	if _, err := sql.Update(dialect.H{"name": "b"}); err != ErrEmptyIn {
+		t.Errorf("err = %v, want ErrEmptyIn", err)
+	}
+	res, err := conn.Query("select name from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0]["name"] != "a" {
+		t.Errorf("name = %v, want a", res[0]["name"])
+	}
+}
@@ -0,0 +1,130 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheckTimeout is the timeout of pinging a connection in HealthCheck.
+var HealthCheckTimeout = 5 * time.Second
+
+// DefaultHealthCheckInterval is the interval of StartHealthCheck used when the
+// given one is not positive.
+var DefaultHealthCheckInterval = 30 * time.Second
+
+// HealthStatus is the status of a connection reported by HealthCheck.
+type HealthStatus struct {
+	// Healthy is whether the ping succeeded.
+	Healthy bool
+	// Err is the error of the ping if it failed.
+	Err error
+	// CheckedAt is the time when the ping started.
+	CheckedAt time.Time
+	// Latency is the duration of the ping.
+	Latency time.Duration
+}
+
+// healthCheck pings the databases of list concurrently, and marks the
+// replicas down or up by the results.
+func (base *Base) healthCheck(list map[string]*sql.DB) map[string]HealthStatus {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]HealthStatus, len(list))
+	)
+	for name, db := range list {
+		wg.Add(1)
+		go func(name string, db *sql.DB) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			err := CommonPing(ctx, db)
+			mu.Lock()
+			statuses[name] = HealthStatus{Healthy: err == nil, Err: err, CheckedAt: start, Latency: time.Since(start)}
+			mu.Unlock()
+		}(name, db)
+	}
+	wg.Wait()
+
+	for _, group := range base.replicaGroups {
+		for _, r := range group.replicas {
+			if status, ok := statuses[r.name]; ok {
+				var down int32
+				if !status.Healthy {
+					down = 1
+				}
+				atomic.StoreInt32(&r.down, down)
+			}
+		}
+	}
+
+	base.healthMu.Lock()
+	base.health = statuses
+	base.healthMu.Unlock()
+
+	result := make(map[string]HealthStatus, len(statuses))
+	for name, status := range statuses {
+		result[name] = status
+	}
+	return result
+}
+
+// Health implements the method HealthChecker.Health. It returns an empty map
+// before the first HealthCheck.
+func (base *Base) Health() map[string]HealthStatus {
+	base.healthMu.Lock()
+	defer base.healthMu.Unlock()
+	result := make(map[string]HealthStatus, len(base.health))
+	for name, status := range base.health {
+		result[name] = status
+	}
+	return result
+}
+
+// startHealthCheck runs healthCheck of list every interval in the background,
+// restarting the former one if it is running.
+func (base *Base) startHealthCheck(list map[string]*sql.DB, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	base.healthMu.Lock()
+	oldStop, oldDone := base.stopHealth, base.healthDone
+	base.stopHealth, base.healthDone = stop, done
+	base.healthMu.Unlock()
+	if oldStop != nil {
+		close(oldStop)
+		<-oldDone
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			base.healthCheck(list)
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// StopHealthCheck implements the method HealthChecker.StopHealthCheck. It waits
+// for the running check to return.
+func (base *Base) StopHealthCheck() {
+	base.healthMu.Lock()
+	stop, done := base.stopHealth, base.healthDone
+	base.stopHealth, base.healthDone = nil, nil
+	base.healthMu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
@@ -0,0 +1,101 @@
+package connection
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStartHealthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"positive", time.Millisecond},
+		{"zero", 0},
+		{"negative", -time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openSqlite(t, Database{})
+			conn.StartHealthCheck(tt.interval)
+			defer conn.StopHealthCheck()
+
+			deadline := time.Now().Add(time.Second)
+			for len(conn.Health()) == 0 {
+				if time.Now().After(deadline) {
+					t.Fatal("no health check in 1s")
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if status := conn.Health()["default"]; !status.Healthy {
+				t.Errorf("status = %+v, want healthy", status)
+			}
+		})
+	}
+}
+
+func TestHealthCheckReplica(t *testing.T) {
+	dir, err := ioutil.TempDir("", "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	replicaDir := filepath.Join(dir, "replica")
+	if err := os.Mkdir(replicaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := GetSqliteDB()
+	_, err = conn.InitDB(map[string]Database{"default": {
+		File:     filepath.Join(dir, "primary.db"),
+		Replicas: []Database{{File: filepath.Join(replicaDir, "replica.db")}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, db := range conn.DbList {
+			_ = db.Close()
+		}
+	}()
+	replica := ReplicaName("default", 0)
+	// every ping opens the file of the replica
+	conn.DbList[replica].SetMaxIdleConns(0)
+
+	check := func(healthy bool, read string) {
+		t.Helper()
+		if status := conn.HealthCheck()[replica]; status.Healthy != healthy {
+			t.Fatalf("replica status = %+v, want healthy %v", status, healthy)
+		}
+		if got := conn.ReadConnection("default"); got != read {
+			t.Errorf("read connection = %q, want %q", got, read)
+		}
+		if err := conn.Ping(context.Background(), replica); (err == nil) != healthy {
+			t.Errorf("ping = %v, want healthy %v", err, healthy)
+		}
+	}
+
+	check(true, replica)
+
+	// the replica is ejected while the file can not be opened
+	if err := os.RemoveAll(replicaDir); err != nil {
+		t.Fatal(err)
+	}
+	check(false, "default")
+
+	// and is routed again after it recovers
+	if err := os.Mkdir(replicaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	check(true, replica)
+
+	if err := conn.Ping(context.Background(), "missing"); err != ErrConnectionNotFound {
+		t.Errorf("ping of missing connection = %v, want ErrConnectionNotFound", err)
+	}
+}
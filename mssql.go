@@ -1,10 +1,12 @@
 package connection
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/url"
 	"sync"
+	"time"
 )
 
 // Mssql is a Connection of mssql.
@@ -18,126 +20,238 @@ type Mssql struct {
 func GetMssqlDB() *Mssql {
 	return &Mssql{
 		DbList: map[string]*sql.DB{},
-		Base:   &Base{DriverName: DriverMssql, Delimiter: "`",},
+		Base:   &Base{DriverName: DriverMssql, Delimiter: "[",},
 	}
 }
 
 // QueryWithConnection implements the method Connection.QueryWithConnection.
 func (db *Mssql) QueryWithConnection(con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList[con], query, args...)
+	return queryContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // ExecWithConnection implements the method Connection.ExecWithConnection.
 func (db *Mssql) ExecWithConnection(con string, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList[con], query, args...)
+	return execContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // Query implements the method Connection.Query.
 func (db *Mssql) Query(query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList["default"], query, args...)
+	return queryContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // Exec implements the method Connection.Exec.
 func (db *Mssql) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList["default"], query, args...)
+	return execContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // InitDB implements the method Connection.InitDB.
-func (db *Mssql) InitDB(cfglist map[string]Database) Connection {
+func (db *Mssql) InitDB(cfglist map[string]Database) (Connection, error) {
 	db.Once.Do(func() {
 		for conn, cfg := range cfglist {
+			sqlDB, err := db.open(cfg)
+			if err != nil {
+				db.initErr = err
+				return
+			}
 
-			if cfg.Dsn == "" {
-				u := &url.URL{
-					Scheme: "mssql",
-					User:   url.UserPassword(cfg.User, cfg.Pwd),
-					Host:   fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
-				}
-
-				params := ""
-				for k, v := range cfg.Params {
-					params += k + "=" + v + "&"
-				}
+			db.DbList[conn] = sqlDB
+			db.initOptions(conn, sqlDB, cfg)
 
-				cfg.Dsn = u.String() + "?" + params[:len(params)-1]
+			if err := db.initReplicas(db.DbList, conn, cfg, db.open); err != nil {
+				db.initErr = err
+				return
 			}
+		}
+	})
+	return db, db.initErr
+}
+
+// open opens the database of cfg.
+func (db *Mssql) open(cfg Database) (*sql.DB, error) {
+	if cfg.Dsn == "" {
+		u := &url.URL{
+			Scheme: "mssql",
+			User:   url.UserPassword(cfg.User, cfg.Pwd),
+			Host:   fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		}
 
-			sqlDB, err := sql.Open("mssql", cfg.Dsn)
+		params := ""
+		for k, v := range cfg.Params {
+			params += k + "=" + v + "&"
+		}
 
-			if sqlDB == nil {
-				panic("invalid connection")
-			}
+		cfg.Dsn = u.String()
+		if params != "" {
+			cfg.Dsn += "?" + params[:len(params)-1]
+		}
+	}
 
-			if err != nil {
-				_ = sqlDB.Close()
-				panic(err.Error())
-			} else {
-				sqlDB.SetMaxIdleConns(cfg.MaxIdleCon)
-				sqlDB.SetMaxOpenConns(cfg.MaxOpenCon)
+	sqlDB, err := sql.Open("mssql", cfg.Dsn)
 
-				db.DbList[conn] = sqlDB
-			}
+	if err != nil {
+		if sqlDB != nil {
+			_ = sqlDB.Close()
 		}
-	})
-	return db
+		return nil, err
+	}
+
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleCon)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenCon)
+	return sqlDB, nil
 }
 
 // BeginTxWithReadUncommitted starts a transaction with level LevelReadUncommitted.
-func (db *Mssql) BeginTxWithReadUncommitted() *sql.Tx {
+func (db *Mssql) BeginTxWithReadUncommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommitted starts a transaction with level LevelReadCommitted.
-func (db *Mssql) BeginTxWithReadCommitted() *sql.Tx {
+func (db *Mssql) BeginTxWithReadCommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableRead starts a transaction with level LevelRepeatableRead.
-func (db *Mssql) BeginTxWithRepeatableRead() *sql.Tx {
+func (db *Mssql) BeginTxWithRepeatableRead() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelRepeatableRead)
 }
 
 // BeginTx starts a transaction with level LevelDefault.
-func (db *Mssql) BeginTx() *sql.Tx {
+func (db *Mssql) BeginTx() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelDefault)
 }
 
 // BeginTxWithLevel starts a transaction with given transaction isolation level.
-func (db *Mssql) BeginTxWithLevel(level sql.IsolationLevel) *sql.Tx {
+func (db *Mssql) BeginTxWithLevel(level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], level)
 }
 
 // BeginTxWithReadUncommittedAndConnection starts a transaction with level LevelReadUncommitted and connection.
-func (db *Mssql) BeginTxWithReadUncommittedAndConnection(conn string) *sql.Tx {
+func (db *Mssql) BeginTxWithReadUncommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommittedAndConnection starts a transaction with level LevelReadCommitted and connection.
-func (db *Mssql) BeginTxWithReadCommittedAndConnection(conn string) *sql.Tx {
+func (db *Mssql) BeginTxWithReadCommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableReadAndConnection starts a transaction with level LevelRepeatableRead and connection.
-func (db *Mssql) BeginTxWithRepeatableReadAndConnection(conn string) *sql.Tx {
+func (db *Mssql) BeginTxWithRepeatableReadAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelRepeatableRead)
 }
 
 // BeginTxAndConnection starts a transaction with level LevelDefault and connection.
-func (db *Mssql) BeginTxAndConnection(conn string) *sql.Tx {
+func (db *Mssql) BeginTxAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelDefault)
 }
 
 // BeginTxWithLevelAndConnection starts a transaction with given transaction isolation level and connection.
-func (db *Mssql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) *sql.Tx {
+func (db *Mssql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], level)
 }
 
 // QueryWithTx is query method within the transaction.
 func (db *Mssql) QueryWithTx(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQueryWithTx(tx, query, args...)
+	return queryWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
 }
 
 // ExecWithTx is exec method within the transaction.
 func (db *Mssql) ExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExecWithTx(tx, query, args...)
+	return execWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
+}
+
+// QueryContext implements the method Connection.QueryContext.
+func (db *Mssql) QueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// ExecContext implements the method Connection.ExecContext.
+func (db *Mssql) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryWithConnectionContext implements the method Connection.QueryWithConnectionContext.
+func (db *Mssql) QueryWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// ExecWithConnectionContext implements the method Connection.ExecWithConnectionContext.
+func (db *Mssql) ExecWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// BeginTxContext starts a transaction with level LevelDefault and the given context.
+func (db *Mssql) BeginTxContext(ctx context.Context) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], sql.LevelDefault)
+}
+
+// BeginTxWithLevelContext starts a transaction with given transaction isolation level and context.
+func (db *Mssql) BeginTxWithLevelContext(ctx context.Context, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], level)
+}
+
+// BeginTxAndConnectionContext starts a transaction with level LevelDefault, connection and context.
+func (db *Mssql) BeginTxAndConnectionContext(ctx context.Context, conn string) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], sql.LevelDefault)
+}
+
+// BeginTxWithLevelAndConnectionContext starts a transaction with given transaction isolation level, connection and context.
+func (db *Mssql) BeginTxWithLevelAndConnectionContext(ctx context.Context, conn string, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], level)
+}
+
+// QueryWithTxContext is query method within the transaction with the given context.
+func (db *Mssql) QueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// ExecWithTxContext is exec method within the transaction with the given context.
+func (db *Mssql) ExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return execWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryRows implements the method Connection.QueryRows.
+func (db *Mssql) QueryRows(query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsContext implements the method Connection.QueryRowsContext.
+func (db *Mssql) QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsWithConnectionContext implements the method Connection.QueryRowsWithConnectionContext.
+func (db *Mssql) QueryRowsWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// QueryRowsWithTxContext is query method within the transaction with the given context
+// which returns a cursor of the results.
+func (db *Mssql) QueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryInto implements the method Connection.QueryInto.
+func (db *Mssql) QueryInto(dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(context.Background(), db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// QueryIntoContext implements the method Connection.QueryIntoContext.
+func (db *Mssql) QueryIntoContext(ctx context.Context, dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(ctx, db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// Ping implements the method Connection.Ping.
+func (db *Mssql) Ping(ctx context.Context, con string) error {
+	return CommonPing(ctx, db.DbList[con])
+}
+
+// HealthCheck implements the method HealthChecker.HealthCheck.
+func (db *Mssql) HealthCheck() map[string]HealthStatus {
+	return db.healthCheck(db.DbList)
+}
+
+// StartHealthCheck implements the method HealthChecker.StartHealthCheck.
+func (db *Mssql) StartHealthCheck(interval time.Duration) {
+	db.startHealthCheck(db.DbList, interval)
 }
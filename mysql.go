@@ -1,8 +1,10 @@
 package connection
 
 import (
+	"context"
 	"database/sql"
 	"sync"
+	"time"
 )
 
 // SQLTx is an in-progress database transaction.
@@ -26,121 +28,232 @@ func GetMysqlDB() *Mysql {
 }
 
 // InitDB implements the method Connection.InitDB.
-func (db *Mysql) InitDB(cfgs map[string]Database) Connection {
+func (db *Mysql) InitDB(cfgs map[string]Database) (Connection, error) {
 	db.Once.Do(func() {
 		for conn, cfg := range cfgs {
-
-			if cfg.Dsn == "" {
-
-				params := ""
-				_, hasCharset := cfg.Params["charset"]
-				for k, v := range cfg.Params {
-					params += k + "=" + v + "&"
-				}
-				if !hasCharset {
-					params += "charset=utf8mb4"
-				} else {
-					params = params[:len(params)-1]
-				}
-
-				cfg.Dsn = cfg.User + ":" + cfg.Pwd + "@tcp(" + cfg.Host + ":" + cfg.Port + ")/" + cfg.Name + "?" + params
+			sqlDB, err := db.open(cfg)
+			if err != nil {
+				db.initErr = err
+				return
 			}
 
-			sqlDB, err := sql.Open("mysql", cfg.Dsn)
+			db.DbList[conn] = sqlDB
+			db.initOptions(conn, sqlDB, cfg)
 
-			if err != nil {
-				if sqlDB != nil {
-					_ = sqlDB.Close()
-				}
-				panic(err.Error())
-			} else {
-				// Largest set up the database connection reduce time wait
-				sqlDB.SetMaxIdleConns(cfg.MaxIdleCon)
-				sqlDB.SetMaxOpenConns(cfg.MaxOpenCon)
-
-				db.DbList[conn] = sqlDB
+			if err := db.initReplicas(db.DbList, conn, cfg, db.open); err != nil {
+				db.initErr = err
+				return
 			}
 		}
 	})
-	return db
+	return db, db.initErr
+}
+
+// open opens the database of cfg.
+func (db *Mysql) open(cfg Database) (*sql.DB, error) {
+	if cfg.Dsn == "" {
+
+		params := ""
+		_, hasCharset := cfg.Params["charset"]
+		for k, v := range cfg.Params {
+			params += k + "=" + v + "&"
+		}
+		if !hasCharset {
+			params += "charset=utf8mb4"
+		} else {
+			params = params[:len(params)-1]
+		}
+
+		cfg.Dsn = cfg.User + ":" + cfg.Pwd + "@tcp(" + cfg.Host + ":" + cfg.Port + ")/" + cfg.Name + "?" + params
+	}
+
+	sqlDB, err := sql.Open("mysql", cfg.Dsn)
+
+	if err != nil {
+		if sqlDB != nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+
+	// Largest set up the database connection reduce time wait
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleCon)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenCon)
+	return sqlDB, nil
 }
 
 // QueryWithConnection implements the method Connection.QueryWithConnection.
 func (db *Mysql) QueryWithConnection(con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList[con], query, args...)
+	return queryContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // ExecWithConnection implements the method Connection.ExecWithConnection.
 func (db *Mysql) ExecWithConnection(con string, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList[con], query, args...)
+	return execContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // Query implements the method Connection.Query.
 func (db *Mysql) Query(query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList["default"], query, args...)
+	return queryContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // Exec implements the method Connection.Exec.
 func (db *Mysql) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList["default"], query, args...)
+	return execContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // BeginTxWithReadUncommitted starts a transaction with level LevelReadUncommitted.
-func (db *Mysql) BeginTxWithReadUncommitted() *sql.Tx {
+func (db *Mysql) BeginTxWithReadUncommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommitted starts a transaction with level LevelReadCommitted.
-func (db *Mysql) BeginTxWithReadCommitted() *sql.Tx {
+func (db *Mysql) BeginTxWithReadCommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableRead starts a transaction with level LevelRepeatableRead.
-func (db *Mysql) BeginTxWithRepeatableRead() *sql.Tx {
+func (db *Mysql) BeginTxWithRepeatableRead() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelRepeatableRead)
 }
 
 // BeginTx starts a transaction with level LevelDefault.
-func (db *Mysql) BeginTx() *sql.Tx {
+func (db *Mysql) BeginTx() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelDefault)
 }
 
 // BeginTxWithLevel starts a transaction with given transaction isolation level.
-func (db *Mysql) BeginTxWithLevel(level sql.IsolationLevel) *sql.Tx {
+func (db *Mysql) BeginTxWithLevel(level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], level)
 }
 
 // BeginTxWithReadUncommittedAndConnection starts a transaction with level LevelReadUncommitted and connection.
-func (db *Mysql) BeginTxWithReadUncommittedAndConnection(conn string) *sql.Tx {
+func (db *Mysql) BeginTxWithReadUncommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommittedAndConnection starts a transaction with level LevelReadCommitted and connection.
-func (db *Mysql) BeginTxWithReadCommittedAndConnection(conn string) *sql.Tx {
+func (db *Mysql) BeginTxWithReadCommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableReadAndConnection starts a transaction with level LevelRepeatableRead and connection.
-func (db *Mysql) BeginTxWithRepeatableReadAndConnection(conn string) *sql.Tx {
+func (db *Mysql) BeginTxWithRepeatableReadAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelRepeatableRead)
 }
 
 // BeginTxAndConnection starts a transaction with level LevelDefault and connection.
-func (db *Mysql) BeginTxAndConnection(conn string) *sql.Tx {
+func (db *Mysql) BeginTxAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelDefault)
 }
 
 // BeginTxWithLevelAndConnection starts a transaction with given transaction isolation level and connection.
-func (db *Mysql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) *sql.Tx {
+func (db *Mysql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], level)
 }
 
 // QueryWithTx is query method within the transaction.
 func (db *Mysql) QueryWithTx(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQueryWithTx(tx, query, args...)
+	return queryWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
 }
 
 // ExecWithTx is exec method within the transaction.
 func (db *Mysql) ExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExecWithTx(tx, query, args...)
+	return execWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
+}
+
+// QueryContext implements the method Connection.QueryContext.
+func (db *Mysql) QueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// ExecContext implements the method Connection.ExecContext.
+func (db *Mysql) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryWithConnectionContext implements the method Connection.QueryWithConnectionContext.
+func (db *Mysql) QueryWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// ExecWithConnectionContext implements the method Connection.ExecWithConnectionContext.
+func (db *Mysql) ExecWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// BeginTxContext starts a transaction with level LevelDefault and the given context.
+func (db *Mysql) BeginTxContext(ctx context.Context) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], sql.LevelDefault)
+}
+
+// BeginTxWithLevelContext starts a transaction with given transaction isolation level and context.
+func (db *Mysql) BeginTxWithLevelContext(ctx context.Context, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], level)
+}
+
+// BeginTxAndConnectionContext starts a transaction with level LevelDefault, connection and context.
+func (db *Mysql) BeginTxAndConnectionContext(ctx context.Context, conn string) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], sql.LevelDefault)
+}
+
+// BeginTxWithLevelAndConnectionContext starts a transaction with given transaction isolation level, connection and context.
+func (db *Mysql) BeginTxWithLevelAndConnectionContext(ctx context.Context, conn string, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], level)
+}
+
+// QueryWithTxContext is query method within the transaction with the given context.
+func (db *Mysql) QueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// ExecWithTxContext is exec method within the transaction with the given context.
+func (db *Mysql) ExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return execWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryRows implements the method Connection.QueryRows.
+func (db *Mysql) QueryRows(query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsContext implements the method Connection.QueryRowsContext.
+func (db *Mysql) QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsWithConnectionContext implements the method Connection.QueryRowsWithConnectionContext.
+func (db *Mysql) QueryRowsWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// QueryRowsWithTxContext is query method within the transaction with the given context
+// which returns a cursor of the results.
+func (db *Mysql) QueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryInto implements the method Connection.QueryInto.
+func (db *Mysql) QueryInto(dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(context.Background(), db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// QueryIntoContext implements the method Connection.QueryIntoContext.
+func (db *Mysql) QueryIntoContext(ctx context.Context, dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(ctx, db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// Ping implements the method Connection.Ping.
+func (db *Mysql) Ping(ctx context.Context, con string) error {
+	return CommonPing(ctx, db.DbList[con])
+}
+
+// HealthCheck implements the method HealthChecker.HealthCheck.
+func (db *Mysql) HealthCheck() map[string]HealthStatus {
+	return db.healthCheck(db.DbList)
+}
+
+// StartHealthCheck implements the method HealthChecker.StartHealthCheck.
+func (db *Mysql) StartHealthCheck(interval time.Duration) {
+	db.startHealthCheck(db.DbList, interval)
 }
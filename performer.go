@@ -3,67 +3,139 @@ package connection
 import (
 	"context"
 	"database/sql"
-	"regexp"
-	"strings"
 )
 
 // CommonQuery is a common method of query.
 func CommonQuery(db *sql.DB, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return CommonQueryContext(context.Background(), db, query, args...)
+}
 
-	rs, err := db.Query(query, args...)
+// CommonQueryContext is a common method of query with the given context.
+func CommonQueryContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db, nil, query, args...)
+}
 
-	if err != nil {
-		panic(err)
+// CommonQueryRowsContext is a common method of query which returns a cursor
+// of the results.
+func CommonQueryRowsContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db, nil, query, args...)
+}
+
+// queryContext is the query method with the options of connection.
+func queryContext(ctx context.Context, db *sql.DB, opts *dbOptions, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return readRows(queryRowsContext(ctx, db, opts, query, args...))
+}
+
+// queryRowsContext is the query method with the options of connection which
+// returns a cursor of the results.
+func queryRowsContext(ctx context.Context, db *sql.DB, opts *dbOptions, query string, args ...interface{}) (*Rows, error) {
+
+	if db == nil {
+		return nil, ErrConnectionNotFound
 	}
 
-	defer func() {
-		if rs != nil {
-			_ = rs.Close()
-		}
-	}()
+	var (
+		rs  *sql.Rows
+		err error
+	)
+	if cache := opts.cache(); cache != nil {
+		rs, err = cache.queryContext(ctx, query, args...)
+	} else {
+		rs, err = db.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newRows(rs, opts.convs())
+}
 
-	col, colErr := rs.Columns()
+// CommonQueryRowsWithTxContext is a common method of query within the transaction
+// which returns a cursor of the results.
+func CommonQueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsWithTxContext(ctx, tx, nil, query, args...)
+}
 
-	if colErr != nil {
-		return nil, colErr
+// queryRowsWithTxContext is the query method within the transaction with the
+// options of connection which returns a cursor of the results.
+func queryRowsWithTxContext(ctx context.Context, tx *sql.Tx, opts *dbOptions, query string, args ...interface{}) (*Rows, error) {
+	var (
+		rs  *sql.Rows
+		err error
+	)
+	if cache := opts.cache(); cache != nil {
+		rs, err = cache.queryTxContext(ctx, tx, query, args...)
+	} else {
+		rs, err = tx.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return newRows(rs, opts.convs())
+}
 
-	typeVal, err := rs.ColumnTypes()
+// readRows reads all the rows of the cursor and closes it.
+func readRows(rows *Rows, err error) ([]map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: regular expressions for sqlite, use the dialect module
-	// tell the drive to reduce the performance loss
-	results := make([]map[string]interface{}, 0)
+	defer func() {
+		_ = rows.Close()
+	}()
 
-	r, _ := regexp.Compile(`\\((.*)\\)`)
-	for rs.Next() {
-		var colVar = make([]interface{}, len(col))
-		for i := 0; i < len(col); i++ {
-			typeName := strings.ToUpper(r.ReplaceAllString(typeVal[i].DatabaseTypeName(), ""))
-			SetColVarType(&colVar, i, typeName)
-		}
-		result := make(map[string]interface{})
-		if scanErr := rs.Scan(colVar...); scanErr != nil {
-			return nil, scanErr
-		}
-		for j := 0; j < len(col); j++ {
-			typeName := strings.ToUpper(r.ReplaceAllString(typeVal[j].DatabaseTypeName(), ""))
-			SetResultValue(&result, col[j], colVar[j], typeName)
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		row := rows.Row()
+		if row == nil {
+			break
 		}
-		results = append(results, result)
+		results = append(results, row)
 	}
-	if err := rs.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 	return results, nil
 }
 
+// CommonQueryIntoContext is a common method of query which scans the results into dst.
+// The dst is a pointer to a slice which receives all the rows, or a pointer to a
+// struct which receives the first row.
+func CommonQueryIntoContext(ctx context.Context, db *sql.DB, dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(ctx, db, nil, dst, query, args...)
+}
+
+// queryIntoContext is the query method with the options of connection which
+// scans the results into dst.
+func queryIntoContext(ctx context.Context, db *sql.DB, opts *dbOptions, dst interface{}, query string, args ...interface{}) error {
+	rows, err := queryRowsContext(ctx, db, opts, query, args...)
+	if isSliceDst(dst) {
+		return scanAll(dst, rows, err)
+	}
+	return scanFirst(dst, rows, err)
+}
+
 // CommonExec is a common method of exec.
 func CommonExec(db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	return CommonExecContext(context.Background(), db, query, args...)
+}
+
+// CommonExecContext is a common method of exec with the given context.
+func CommonExecContext(ctx context.Context, db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db, nil, query, args...)
+}
+
+// execContext is the exec method with the options of connection.
+func execContext(ctx context.Context, db *sql.DB, opts *dbOptions, query string, args ...interface{}) (sql.Result, error) {
 
-	rs, err := db.Exec(query, args...)
+	if db == nil {
+		return nil, ErrConnectionNotFound
+	}
+
+	if cache := opts.cache(); cache != nil {
+		return cache.execContext(ctx, query, args...)
+	}
+
+	rs, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -72,71 +144,64 @@ func CommonExec(db *sql.DB, query string, args ...interface{}) (sql.Result, erro
 
 // CommonQueryWithTx is a common method of query.
 func CommonQueryWithTx(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return CommonQueryWithTxContext(context.Background(), tx, query, args...)
+}
 
-	rs, err := tx.Query(query, args...)
+// CommonQueryWithTxContext is a common method of query within the transaction with the given context.
+func CommonQueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryWithTxContext(ctx, tx, nil, query, args...)
+}
 
-	if err != nil {
-		panic(err)
-	}
+// queryWithTxContext is the query method within the transaction with the
+// options of connection.
+func queryWithTxContext(ctx context.Context, tx *sql.Tx, opts *dbOptions, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return readRows(queryRowsWithTxContext(ctx, tx, opts, query, args...))
+}
 
-	defer func() {
-		if rs != nil {
-			_ = rs.Close()
-		}
-	}()
+// CommonExecWithTx is a common method of exec.
+func CommonExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return CommonExecWithTxContext(context.Background(), tx, query, args...)
+}
 
-	col, colErr := rs.Columns()
+// CommonExecWithTxContext is a common method of exec within the transaction with the given context.
+func CommonExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return execWithTxContext(ctx, tx, nil, query, args...)
+}
 
-	if colErr != nil {
-		return nil, colErr
+// execWithTxContext is the exec method within the transaction with the
+// options of connection.
+func execWithTxContext(ctx context.Context, tx *sql.Tx, opts *dbOptions, query string, args ...interface{}) (sql.Result, error) {
+	if cache := opts.cache(); cache != nil {
+		return cache.execTxContext(ctx, tx, query, args...)
 	}
 
-	typeVal, err := rs.ColumnTypes()
+	rs, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	return rs, nil
+}
 
-	// TODO: regular expressions for sqlite, use the dialect module
-	// tell the drive to reduce the performance loss
-	results := make([]map[string]interface{}, 0)
-
-	r, _ := regexp.Compile(`\\((.*)\\)`)
-	for rs.Next() {
-		var colVar = make([]interface{}, len(col))
-		for i := 0; i < len(col); i++ {
-			typeName := strings.ToUpper(r.ReplaceAllString(typeVal[i].DatabaseTypeName(), ""))
-			SetColVarType(&colVar, i, typeName)
-		}
-		result := make(map[string]interface{})
-		if scanErr := rs.Scan(colVar...); scanErr != nil {
-			return nil, scanErr
-		}
-		for j := 0; j < len(col); j++ {
-			typeName := strings.ToUpper(r.ReplaceAllString(typeVal[j].DatabaseTypeName(), ""))
-			SetResultValue(&result, col[j], colVar[j], typeName)
-		}
-		results = append(results, result)
-	}
-	if err := rs.Err(); err != nil {
-		return nil, err
-	}
-	return results, nil
+// CommonBeginTxWithLevel starts a transaction with given transaction isolation level and db connection.
+func CommonBeginTxWithLevel(db *sql.DB, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(context.Background(), db, level)
 }
 
-// CommonExecWithTx is a common method of exec.
-func CommonExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	rs, err := tx.Exec(query, args...)
-	if err != nil {
-		return nil, err
+// CommonBeginTxWithLevelContext starts a transaction with given context, transaction isolation
+// level and db connection. The transaction is rolled back if the context is done before it is
+// committed.
+func CommonBeginTxWithLevelContext(ctx context.Context, db *sql.DB, level sql.IsolationLevel) (*sql.Tx, error) {
+	if db == nil {
+		return nil, ErrConnectionNotFound
 	}
-	return rs, nil
+	return db.BeginTx(ctx, &sql.TxOptions{Isolation: level})
 }
 
-// CommonBeginTxWithLevel starts a transaction with given transaction isolation level and db connection.
-func CommonBeginTxWithLevel(db *sql.DB, level sql.IsolationLevel) *sql.Tx {
-	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: level})
-	if err != nil {
-		panic(err)
+// CommonPing is a common method of ping, which verifies that the database is
+// reachable.
+func CommonPing(ctx context.Context, db *sql.DB) error {
+	if db == nil {
+		return ErrConnectionNotFound
 	}
-	return tx
+	return db.PingContext(ctx)
 }
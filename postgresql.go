@@ -1,11 +1,12 @@
 package connection
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
-	"strings"
+	"github.com/chenhg5/go-sql/dialect"
 	"sync"
+	"time"
 )
 
 // Postgresql is a Connection of mssql.
@@ -25,123 +26,225 @@ func GetPostgresqlDB() *Postgresql {
 
 // QueryWithConnection implements the method Connection.QueryWithConnection.
 func (db *Postgresql) QueryWithConnection(con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList[con], filterQuery(query), args...)
+	return queryContext(context.Background(), db.DbList[con], db.connOptions(con), rebind(query), args...)
 }
 
 // ExecWithConnection implements the method Connection.ExecWithConnection.
 func (db *Postgresql) ExecWithConnection(con string, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList[con], filterQuery(query), args...)
+	return execContext(context.Background(), db.DbList[con], db.connOptions(con), rebind(query), args...)
 }
 
 // Query implements the method Connection.Query.
 func (db *Postgresql) Query(query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList["default"], filterQuery(query), args...)
+	return queryContext(context.Background(), db.DbList["default"], db.connOptions("default"), rebind(query), args...)
 }
 
 // Exec implements the method Connection.Exec.
 func (db *Postgresql) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList["default"], filterQuery(query), args...)
+	return execContext(context.Background(), db.DbList["default"], db.connOptions("default"), rebind(query), args...)
 }
 
-func filterQuery(query string) string {
-	queCount := strings.Count(query, "?")
-	for i := 1; i < queCount+1; i++ {
-		query = strings.Replace(query, "?", "$"+strconv.Itoa(i), 1)
-	}
-	query = strings.Replace(query, "`", "", -1)
-	// TODO: add " to the keyword
-	return strings.Replace(query, "by order ", `by "order" `, -1)
+// rebind replaces the "?" placeholders of query with the ones of postgresql.
+func rebind(query string) string {
+	return dialect.GetDialectByDriver(DriverPostgresql).Rebind(query)
 }
 
 // InitDB implements the method Connection.InitDB.
-func (db *Postgresql) InitDB(cfgList map[string]Database) Connection {
+func (db *Postgresql) InitDB(cfgList map[string]Database) (Connection, error) {
 	db.Once.Do(func() {
 		for conn, cfg := range cfgList {
-
-			if cfg.Dsn == "" {
-
-				params := ""
-				_, hasSSLmode := cfg.Params["charset"]
-				for k, v := range cfg.Params {
-					params += k + "=" + v + " "
-				}
-				if !hasSSLmode {
-					params += "sslmode=disable"
-				} else {
-					params = params[:len(params)-1]
-				}
-
-				cfg.Dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s ",
-					cfg.Host, cfg.Port, cfg.User, cfg.Pwd, cfg.Name) + params
-			}
-
-			sqlDB, err := sql.Open("postgres", cfg.Dsn)
+			sqlDB, err := db.open(cfg)
 			if err != nil {
-				panic(err)
+				db.initErr = err
+				return
 			}
 
 			db.DbList[conn] = sqlDB
+			db.initOptions(conn, sqlDB, cfg)
+
+			if err := db.initReplicas(db.DbList, conn, cfg, db.open); err != nil {
+				db.initErr = err
+				return
+			}
 		}
 	})
-	return db
+	return db, db.initErr
+}
+
+// open opens the database of cfg.
+func (db *Postgresql) open(cfg Database) (*sql.DB, error) {
+	if cfg.Dsn == "" {
+
+		params := ""
+		_, hasSSLmode := cfg.Params["charset"]
+		for k, v := range cfg.Params {
+			params += k + "=" + v + " "
+		}
+		if !hasSSLmode {
+			params += "sslmode=disable"
+		} else {
+			params = params[:len(params)-1]
+		}
+
+		cfg.Dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s ",
+			cfg.Host, cfg.Port, cfg.User, cfg.Pwd, cfg.Name) + params
+	}
+
+	return sql.Open("postgres", cfg.Dsn)
 }
 
 // BeginTxWithReadUncommitted starts a transaction with level LevelReadUncommitted.
-func (db *Postgresql) BeginTxWithReadUncommitted() *sql.Tx {
+func (db *Postgresql) BeginTxWithReadUncommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommitted starts a transaction with level LevelReadCommitted.
-func (db *Postgresql) BeginTxWithReadCommitted() *sql.Tx {
+func (db *Postgresql) BeginTxWithReadCommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableRead starts a transaction with level LevelRepeatableRead.
-func (db *Postgresql) BeginTxWithRepeatableRead() *sql.Tx {
+func (db *Postgresql) BeginTxWithRepeatableRead() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelRepeatableRead)
 }
 
 // BeginTx starts a transaction with level LevelDefault.
-func (db *Postgresql) BeginTx() *sql.Tx {
+func (db *Postgresql) BeginTx() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelDefault)
 }
 
 // BeginTxWithLevel starts a transaction with given transaction isolation level.
-func (db *Postgresql) BeginTxWithLevel(level sql.IsolationLevel) *sql.Tx {
+func (db *Postgresql) BeginTxWithLevel(level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], level)
 }
 
 // BeginTxWithReadUncommittedAndConnection starts a transaction with level LevelReadUncommitted and connection.
-func (db *Postgresql) BeginTxWithReadUncommittedAndConnection(conn string) *sql.Tx {
+func (db *Postgresql) BeginTxWithReadUncommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommittedAndConnection starts a transaction with level LevelReadCommitted and connection.
-func (db *Postgresql) BeginTxWithReadCommittedAndConnection(conn string) *sql.Tx {
+func (db *Postgresql) BeginTxWithReadCommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableReadAndConnection starts a transaction with level LevelRepeatableRead and connection.
-func (db *Postgresql) BeginTxWithRepeatableReadAndConnection(conn string) *sql.Tx {
+func (db *Postgresql) BeginTxWithRepeatableReadAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelRepeatableRead)
 }
 
 // BeginTxAndConnection starts a transaction with level LevelDefault and connection.
-func (db *Postgresql) BeginTxAndConnection(conn string) *sql.Tx {
+func (db *Postgresql) BeginTxAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelDefault)
 }
 
 // BeginTxWithLevelAndConnection starts a transaction with given transaction isolation level and connection.
-func (db *Postgresql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) *sql.Tx {
+func (db *Postgresql) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], level)
 }
 
 // QueryWithTx is query method within the transaction.
 func (db *Postgresql) QueryWithTx(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQueryWithTx(tx, filterQuery(query), args...)
+	return queryWithTxContext(context.Background(), tx, db.txOptions(context.Background()), rebind(query), args...)
 }
 
 // ExecWithTx is exec method within the transaction.
 func (db *Postgresql) ExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExecWithTx(tx, filterQuery(query), args...)
+	return execWithTxContext(context.Background(), tx, db.txOptions(context.Background()), rebind(query), args...)
+}
+
+// QueryContext implements the method Connection.QueryContext.
+func (db *Postgresql) QueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList["default"], db.connOptions("default"), rebind(query), args...)
+}
+
+// ExecContext implements the method Connection.ExecContext.
+func (db *Postgresql) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList["default"], db.connOptions("default"), rebind(query), args...)
+}
+
+// QueryWithConnectionContext implements the method Connection.QueryWithConnectionContext.
+func (db *Postgresql) QueryWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList[con], db.connOptions(con), rebind(query), args...)
+}
+
+// ExecWithConnectionContext implements the method Connection.ExecWithConnectionContext.
+func (db *Postgresql) ExecWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList[con], db.connOptions(con), rebind(query), args...)
+}
+
+// BeginTxContext starts a transaction with level LevelDefault and the given context.
+func (db *Postgresql) BeginTxContext(ctx context.Context) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], sql.LevelDefault)
+}
+
+// BeginTxWithLevelContext starts a transaction with given transaction isolation level and context.
+func (db *Postgresql) BeginTxWithLevelContext(ctx context.Context, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], level)
+}
+
+// BeginTxAndConnectionContext starts a transaction with level LevelDefault, connection and context.
+func (db *Postgresql) BeginTxAndConnectionContext(ctx context.Context, conn string) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], sql.LevelDefault)
+}
+
+// BeginTxWithLevelAndConnectionContext starts a transaction with given transaction isolation level, connection and context.
+func (db *Postgresql) BeginTxWithLevelAndConnectionContext(ctx context.Context, conn string, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], level)
+}
+
+// QueryWithTxContext is query method within the transaction with the given context.
+func (db *Postgresql) QueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryWithTxContext(ctx, tx, db.txOptions(ctx), rebind(query), args...)
+}
+
+// ExecWithTxContext is exec method within the transaction with the given context.
+func (db *Postgresql) ExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return execWithTxContext(ctx, tx, db.txOptions(ctx), rebind(query), args...)
+}
+
+// QueryRows implements the method Connection.QueryRows.
+func (db *Postgresql) QueryRows(query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(context.Background(), db.DbList["default"], db.connOptions("default"), rebind(query), args...)
+}
+
+// QueryRowsContext implements the method Connection.QueryRowsContext.
+func (db *Postgresql) QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList["default"], db.connOptions("default"), rebind(query), args...)
+}
+
+// QueryRowsWithConnectionContext implements the method Connection.QueryRowsWithConnectionContext.
+func (db *Postgresql) QueryRowsWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList[con], db.connOptions(con), rebind(query), args...)
+}
+
+// QueryRowsWithTxContext is query method within the transaction with the given context
+// which returns a cursor of the results.
+func (db *Postgresql) QueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsWithTxContext(ctx, tx, db.txOptions(ctx), rebind(query), args...)
+}
+
+// QueryInto implements the method Connection.QueryInto.
+func (db *Postgresql) QueryInto(dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(context.Background(), db.DbList["default"], db.connOptions("default"), dst, rebind(query), args...)
+}
+
+// QueryIntoContext implements the method Connection.QueryIntoContext.
+func (db *Postgresql) QueryIntoContext(ctx context.Context, dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(ctx, db.DbList["default"], db.connOptions("default"), dst, rebind(query), args...)
+}
+
+// Ping implements the method Connection.Ping.
+func (db *Postgresql) Ping(ctx context.Context, con string) error {
+	return CommonPing(ctx, db.DbList[con])
+}
+
+// HealthCheck implements the method HealthChecker.HealthCheck.
+func (db *Postgresql) HealthCheck() map[string]HealthStatus {
+	return db.healthCheck(db.DbList)
+}
+
+// StartHealthCheck implements the method HealthChecker.StartHealthCheck.
+func (db *Postgresql) StartHealthCheck(interval time.Duration) {
+	db.startHealthCheck(db.DbList, interval)
 }
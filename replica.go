@@ -0,0 +1,136 @@
+package connection
+
+import (
+	"database/sql"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ReplicaPolicy is the policy which picks the replica of a connection for the
+// reads.
+type ReplicaPolicy string
+
+const (
+	// RoundRobin picks the replicas in turn, which is the default policy.
+	RoundRobin ReplicaPolicy = "round_robin"
+	// Random picks a replica at random.
+	Random ReplicaPolicy = "random"
+	// LeastConnections picks the replica with the fewest connections in use.
+	LeastConnections ReplicaPolicy = "least_connections"
+)
+
+// ReplicaName return the name of the i-th replica of the connection con, by
+// which it is kept in the DbList of the driver.
+func ReplicaName(con string, i int) string {
+	return con + ".replica" + strconv.Itoa(i)
+}
+
+// replica is a read replica of a connection.
+type replica struct {
+	name string
+	db   *sql.DB
+	// down is set to 1 by the health check while the replica is unhealthy.
+	down int32
+}
+
+// replicaGroup is the replicas of a connection, which is the primary of them.
+type replicaGroup struct {
+	replicas []*replica
+	policy   ReplicaPolicy
+	next     uint32
+	// sticky is the Database.StickyPrimary of the primary.
+	sticky time.Duration
+	// stickyUntil is the unix nano time until which the reads go to the
+	// primary after a write.
+	stickyUntil int64
+}
+
+// pick return the name of the healthy replica given by the policy, or false
+// if all of them are down.
+func (group *replicaGroup) pick() (string, bool) {
+	healthy := make([]*replica, 0, len(group.replicas))
+	for _, r := range group.replicas {
+		if atomic.LoadInt32(&r.down) == 0 {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return "", false
+	}
+
+	switch group.policy {
+	case Random:
+		return healthy[rand.Intn(len(healthy))].name, true
+	case LeastConnections:
+		best, inUse := 0, -1
+		for i, r := range healthy {
+			if n := r.db.Stats().InUse; inUse < 0 || n < inUse {
+				best, inUse = i, n
+			}
+		}
+		return healthy[best].name, true
+	default:
+		i := atomic.AddUint32(&group.next, 1) - 1
+		return healthy[int(i%uint32(len(healthy)))].name, true
+	}
+}
+
+// ReadConnection implements the method ReplicaRouter.ReadConnection. The reads
+// go to the primary when all the replicas are down, or within the sticky
+// duration after a write.
+func (base *Base) ReadConnection(con string) string {
+	group, ok := base.replicaGroups[con]
+	if !ok {
+		return con
+	}
+	if time.Now().UnixNano() < atomic.LoadInt64(&group.stickyUntil) {
+		return con
+	}
+	if name, ok := group.pick(); ok {
+		return name
+	}
+	return con
+}
+
+// Written implements the method ReplicaRouter.Written.
+func (base *Base) Written(con string) {
+	group, ok := base.replicaGroups[con]
+	if !ok || group.sticky <= 0 {
+		return
+	}
+	atomic.StoreInt64(&group.stickyUntil, time.Now().Add(group.sticky).UnixNano())
+}
+
+// initReplicas opens the replicas of the connection con by the open function
+// of the driver, and keeps them in list by ReplicaName.
+func (base *Base) initReplicas(list map[string]*sql.DB, con string, cfg Database,
+	open func(Database) (*sql.DB, error)) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	group := &replicaGroup{policy: cfg.ReplicaPolicy, sticky: cfg.StickyPrimary}
+	for i, replicaCfg := range cfg.Replicas {
+		// the options of result values are the same as the primary
+		if replicaCfg.StmtCacheSize == 0 {
+			replicaCfg.StmtCacheSize = cfg.StmtCacheSize
+		}
+		if replicaCfg.Converters == nil {
+			replicaCfg.Converters = cfg.Converters
+		}
+		sqlDB, err := open(replicaCfg)
+		if err != nil {
+			return err
+		}
+		name := ReplicaName(con, i)
+		list[name] = sqlDB
+		base.initOptions(name, sqlDB, replicaCfg)
+		group.replicas = append(group.replicas, &replica{name: name, db: sqlDB})
+	}
+	if base.replicaGroups == nil {
+		base.replicaGroups = make(map[string]*replicaGroup)
+	}
+	base.replicaGroups[con] = group
+	return nil
+}
@@ -0,0 +1,81 @@
+package connection
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chenhg5/go-sql/dialect"
+)
+
+// openSqliteReplica opens the sqlite files of the primary and one replica in
+// a temporary directory, which is removed by the returned function. The
+// replica is a separate database which does not get the writes.
+func openSqliteReplica(t *testing.T, sticky time.Duration) (*Sqlite, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := GetSqliteDB()
+	_, err = conn.InitDB(map[string]Database{"default": {
+		File:          filepath.Join(dir, "primary.db"),
+		Replicas:      []Database{{File: filepath.Join(dir, "replica.db")}},
+		StickyPrimary: sticky,
+	}})
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	for _, db := range conn.DbList {
+		if _, err := db.Exec("create table users (id integer primary key, name text)"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return conn, func() {
+		for _, db := range conn.DbList {
+			_ = db.Close()
+		}
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestStickyPrimary(t *testing.T) {
+	tests := []struct {
+		name    string
+		sticky  time.Duration
+		primary bool
+		wait    time.Duration
+		want    int64
+	}{
+		{name: "replica", want: 0},
+		{name: "use primary", primary: true, want: 1},
+		{name: "sticky", sticky: time.Minute, want: 1},
+		{name: "sticky expired", sticky: 10 * time.Millisecond, wait: 50 * time.Millisecond, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, closeAll := openSqliteReplica(t, tt.sticky)
+			defer closeAll()
+
+			if _, err := WithDriver(conn).Table("users").Insert(dialect.H{"name": "a"}); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(tt.wait)
+
+			sql := WithDriver(conn).Table("users")
+			if tt.primary {
+				sql.UsePrimary()
+			}
+			count, err := sql.Count()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.want {
+				t.Errorf("count = %d, want %d", count, tt.want)
+			}
+		})
+	}
+}
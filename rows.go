@@ -0,0 +1,124 @@
+package connection
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Rows is a cursor of the query results, which converts one row at a time
+// instead of reading all of them into memory. The cursor must be closed after
+// use, and the query is canceled once the context of it is done.
+//
+//	rows, err := conn.QueryRows("select * from users")
+//	if err != nil {
+//		return err
+//	}
+//	defer rows.Close()
+//	for rows.Next() {
+//		user := rows.Row()
+//	}
+//	return rows.Err()
+type Rows struct {
+	rs      *sql.Rows
+	columns []string
+	// scanners are resolved once from the column types, and the destinations
+	// of them are reused across the rows.
+	scanners []columnScanner
+	dest     []interface{}
+	// converters are the converters of the columns, which are nil for the
+	// columns without one.
+	converters []Converter
+	err        error
+}
+
+// newRows wraps the sql.Rows and resolves the column types of it, converting
+// the values with the local converters of the connection or the registered
+// ones.
+func newRows(rs *sql.Rows, local Converters) (*Rows, error) {
+	col, err := rs.Columns()
+	if err != nil {
+		_ = rs.Close()
+		return nil, err
+	}
+
+	typeVal, err := rs.ColumnTypes()
+	if err != nil {
+		_ = rs.Close()
+		return nil, err
+	}
+
+	scanners := make([]columnScanner, len(col))
+	dest := make([]interface{}, len(col))
+	var convs []Converter
+	for i := 0; i < len(col); i++ {
+		typeName := normalizeTypeName(typeVal[i].DatabaseTypeName())
+		if converter := converterOf(local, typeName); converter != nil {
+			if convs == nil {
+				convs = make([]Converter, len(col))
+			}
+			convs[i] = converter
+			// the converter gets the value as the driver returns it
+			typeName = ""
+		}
+		scanners[i] = newColumnScanner(typeName)
+		dest[i] = scanners[i].dest
+	}
+
+	return &Rows{rs: rs, columns: col, scanners: scanners, dest: dest, converters: convs}, nil
+}
+
+// Next prepares the next row for reading with the Row or Scan method. It returns
+// false when there is no next row or an error happened, which is reported by Err.
+func (r *Rows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	return r.rs.Next()
+}
+
+// Row converts the current row into a map keyed by the column names. It
+// returns nil if the conversion failed, and the error is reported by Err.
+func (r *Rows) Row() map[string]interface{} {
+	if err := r.rs.Scan(r.dest...); err != nil {
+		r.fail(err)
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(r.columns))
+	for i, column := range r.columns {
+		value := r.scanners[i].value()
+		if r.converters != nil && r.converters[i] != nil && value != nil {
+			var err error
+			if value, err = r.converters[i](value); err != nil {
+				r.fail(fmt.Errorf("connection: convert column %q: %w", column, err))
+				return nil
+			}
+		}
+		result[column] = value
+	}
+	return result
+}
+
+// fail records the error and closes the cursor.
+func (r *Rows) fail(err error) {
+	r.err = err
+	_ = r.rs.Close()
+}
+
+// Columns returns the column names.
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Err returns the error encountered during iteration, if any.
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.rs.Err()
+}
+
+// Close closes the Rows and releases the connection of it.
+func (r *Rows) Close() error {
+	return r.rs.Close()
+}
@@ -0,0 +1,303 @@
+package connection
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	errConvert := errors.New("convert")
+	conn := openSqlite(t, Database{Converters: Converters{"BADTYPE": func(interface{}) (interface{}, error) {
+		return nil, errConvert
+	}}})
+	mustExec(t, conn,
+		"create table users (id integer primary key, name text, bad badtype)",
+		"insert into users (id, name, bad) values (1, 'a', null), (2, 'b', null), (3, 'c', 'x')",
+	)
+
+	t.Run("row", func(t *testing.T) {
+		rows, err := conn.QueryRows("select id, name from users where id < ? order by id", 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if !reflect.DeepEqual(rows.Columns(), []string{"id", "name"}) {
+			t.Errorf("columns = %v", rows.Columns())
+		}
+		var got []map[string]interface{}
+		for rows.Next() {
+			got = append(got, rows.Row())
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		want := []map[string]interface{}{{"id": int64(1), "name": "a"}, {"id": int64(2), "name": "b"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("rows = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("scan", func(t *testing.T) {
+		rows, err := WithDriver(conn).Table("users").Select("id", "name").OrderBy("id").Cursor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var user mappedUser
+			if err := rows.Scan(&user); err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, user.Name)
+		}
+		if err := rows.Err(); err != nil || !reflect.DeepEqual(names, []string{"a", "b", "c"}) {
+			t.Errorf("names = %v, err = %v", names, err)
+		}
+	})
+
+	t.Run("converter error", func(t *testing.T) {
+		rows, err := conn.QueryRows("select id, bad from users order by id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		n := 0
+		for rows.Next() {
+			if rows.Row() != nil {
+				n++
+			}
+		}
+		if n != 2 || !errors.Is(rows.Err(), errConvert) {
+			t.Errorf("got %d rows and err %v, want 2 rows and the converter error", n, rows.Err())
+		}
+	})
+
+	t.Run("close early", func(t *testing.T) {
+		rows, err := conn.QueryRows("select id from users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Next()
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// the only connection is released by Close
+		if _, err := conn.Query("select id from users"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("all into", func(t *testing.T) {
+		var users []*mappedUser
+		if err := WithDriver(conn).Table("users").Select("id", "name").Where("id", ">", 1).OrderBy("id").AllInto(&users); err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 || users[0].ID != 2 || users[1].Name != "c" {
+			t.Errorf("users = %v", users)
+		}
+
+		var user mappedUser
+		if err := WithDriver(conn).Table("users").Select("id", "name").OrderBy("id desc").FirstInto(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != 3 {
+			t.Errorf("user = %+v, want the id 3", user)
+		}
+	})
+}
+
+const benchRows = 10000
+
+// openBigSqlite opens the sqlite database with the table big of benchRows rows.
+func openBigSqlite(b *testing.B) *Sqlite {
+	b.Helper()
+	conn := openSqlite(b, Database{})
+	mustExec(b, conn, "create table big (id integer primary key, name text, score real, created_at text)")
+
+	tx, err := conn.BeginTx()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < benchRows; i++ {
+		if _, err := conn.ExecWithTx(tx, "insert into big (id, name, score, created_at) values (?, ?, ?, ?)",
+			i, "name"+strconv.Itoa(i), float64(i)/3, "2020-01-01 00:00:00"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+	return conn
+}
+
+type bigRow struct {
+	ID        int64   `db:"id"`
+	Name      string  `db:"name"`
+	Score     float64 `db:"score"`
+	CreatedAt string  `db:"created_at"`
+}
+
+func BenchmarkQuery(b *testing.B) {
+	conn := openBigSqlite(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := conn.Query("select * from big")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(res) != benchRows {
+			b.Fatalf("got %d rows, want %d", len(res), benchRows)
+		}
+	}
+}
+
+// BenchmarkQueryLegacy is the baseline of BenchmarkQuery, which converts the
+// results as Rows did before the column scanners.
+func BenchmarkQueryLegacy(b *testing.B) {
+	conn := openBigSqlite(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		res, err := legacyQuery(conn.DbList["default"], "select * from big")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(res) != benchRows {
+			b.Fatalf("got %d rows, want %d", len(res), benchRows)
+		}
+	}
+}
+
+// legacyQuery reads the results by the former conversion, which matches the
+// type name of every value against the type lists by Contains.
+func legacyQuery(db *sql.DB, query string) ([]map[string]interface{}, error) {
+	rs, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	col, err := rs.Columns()
+	if err != nil {
+		return nil, err
+	}
+	typeVal, err := rs.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	r, _ := regexp.Compile(`\\((.*)\\)`)
+	typeNames := make([]string, len(col))
+	for i := 0; i < len(col); i++ {
+		typeNames[i] = strings.ToUpper(r.ReplaceAllString(typeVal[i].DatabaseTypeName(), ""))
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for rs.Next() {
+		colVar := make([]interface{}, len(col))
+		for i := 0; i < len(col); i++ {
+			colVar[i] = legacyColVar(typeNames[i])
+		}
+		if err := rs.Scan(colVar...); err != nil {
+			return nil, err
+		}
+		result := make(map[string]interface{}, len(col))
+		for j := 0; j < len(col); j++ {
+			result[col[j]] = legacyValue(colVar[j], typeNames[j])
+		}
+		results = append(results, result)
+	}
+	return results, rs.Err()
+}
+
+func legacyColVar(typeName string) interface{} {
+	switch {
+	case Contains(DT(typeName), BoolTypeList):
+		return new(sql.NullBool)
+	case Contains(DT(typeName), IntTypeList):
+		return new(sql.NullInt64)
+	case Contains(DT(typeName), FloatTypeList):
+		return new(sql.NullFloat64)
+	case Contains(DT(typeName), UintTypeList):
+		return new([]uint8)
+	case Contains(DT(typeName), StringTypeList):
+		return new(sql.NullString)
+	default:
+		return new(interface{})
+	}
+}
+
+func legacyValue(colVar interface{}, typeName string) interface{} {
+	switch {
+	case Contains(DT(typeName), BoolTypeList):
+		if v := *(colVar.(*sql.NullBool)); v.Valid {
+			return v.Bool
+		}
+		return nil
+	case Contains(DT(typeName), IntTypeList):
+		if v := *(colVar.(*sql.NullInt64)); v.Valid {
+			return v.Int64
+		}
+		return nil
+	case Contains(DT(typeName), FloatTypeList):
+		if v := *(colVar.(*sql.NullFloat64)); v.Valid {
+			return v.Float64
+		}
+		return nil
+	case Contains(DT(typeName), UintTypeList):
+		return *(colVar.(*[]uint8))
+	case Contains(DT(typeName), StringTypeList):
+		if v := *(colVar.(*sql.NullString)); v.Valid {
+			return v.String
+		}
+		return nil
+	default:
+		return *(colVar.(*interface{}))
+	}
+}
+
+func BenchmarkQueryRows(b *testing.B) {
+	conn := openBigSqlite(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows, err := conn.QueryRows("select * from big")
+		if err != nil {
+			b.Fatal(err)
+		}
+		n := 0
+		for rows.Next() {
+			_ = rows.Row()
+			n++
+		}
+		if err := rows.Err(); err != nil {
+			b.Fatal(err)
+		}
+		_ = rows.Close()
+		if n != benchRows {
+			b.Fatalf("got %d rows, want %d", n, benchRows)
+		}
+	}
+}
+
+func BenchmarkQueryInto(b *testing.B) {
+	conn := openBigSqlite(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var res []bigRow
+		if err := conn.QueryInto(&res, "select * from big"); err != nil {
+			b.Fatal(err)
+		}
+		if len(res) != benchRows {
+			b.Fatalf("got %d rows, want %d", len(res), benchRows)
+		}
+	}
+}
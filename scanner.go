@@ -0,0 +1,297 @@
+package connection
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/chenhg5/go-sql/dialect"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// structField is a field of struct which is mapped to a column.
+type structField struct {
+	// index is the index sequence for reflect.Value.FieldByIndex, which walks
+	// through the embedded structs.
+	index []int
+	// column is the column name given by the `db` tag, or the snake case of
+	// the field name if the tag is absent.
+	column string
+	// pk marks the primary key, which is written back by InsertStruct and used
+	// as the condition by UpdateStruct.
+	pk bool
+	// omitempty skips the field when it has the zero value on writing.
+	omitempty bool
+	// readonly skips the field on writing.
+	readonly bool
+}
+
+// structInfo is the column mapping of a struct type.
+type structInfo struct {
+	fields   []structField
+	byColumn map[string]*structField
+}
+
+// structInfoCache caches the *structInfo of reflect.Type.
+var structInfoCache sync.Map
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// getStructInfo return the column mapping of given struct type.
+func getStructInfo(t reflect.Type) *structInfo {
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo)
+	}
+	info := &structInfo{byColumn: make(map[string]*structField)}
+	collectFields(t, nil, info)
+	for i := range info.fields {
+		info.byColumn[strings.ToLower(info.fields[i].column)] = &info.fields[i]
+	}
+	structInfoCache.Store(t, info)
+	return info
+}
+
+func collectFields(t reflect.Type, index []int, info *structInfo) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("db")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		// the embedded struct without tag is flattened
+		if f.Anonymous && !hasTag {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !isScanner(ft) {
+				collectFields(ft, fieldIndex, info)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		field := structField{index: fieldIndex, column: opts[0]}
+		if field.column == "" {
+			field.column = snakeCase(f.Name)
+		}
+		for _, opt := range opts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "pk":
+				field.pk = true
+			case "omitempty":
+				field.omitempty = true
+			case "readonly":
+				field.readonly = true
+			}
+		}
+		info.fields = append(info.fields, field)
+	}
+}
+
+// isScanner reports whether the values of t are scanned as a whole, such as
+// time.Time and sql.NullString.
+func isScanner(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(scannerType) || t.PkgPath() == "time" && t.Name() == "Time"
+}
+
+// snakeCase turns the field name into snake case, e.g. "UserID" to "user_id".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) ||
+				i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but allocates the nil
+// pointers of embedded structs.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldValue is like reflect.Value.FieldByIndex but reports false instead of
+// panicking when an embedded struct pointer is nil.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// structValue return the struct value of v, which is a struct or a pointer to struct.
+func structValue(v interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("connection: expect a struct or pointer to struct, got %T", v)
+	}
+	return rv, nil
+}
+
+// structValues return the writable column/value pairs of the struct and the
+// primary key fields of it.
+func structValues(rv reflect.Value) (dialect.H, []*structField) {
+	var (
+		info   = getStructInfo(rv.Type())
+		values = make(dialect.H, len(info.fields))
+		pks    = make([]*structField, 0)
+	)
+	for i := range info.fields {
+		field := &info.fields[i]
+		if field.pk {
+			pks = append(pks, field)
+		}
+		if field.readonly {
+			continue
+		}
+		fv, ok := fieldValue(rv, field.index)
+		if !ok {
+			continue
+		}
+		if fv.IsZero() && (field.omitempty || field.pk) {
+			continue
+		}
+		values[field.column] = fv.Interface()
+	}
+	return values, pks
+}
+
+// Scan copies the columns of the current row into dst, which is a pointer to
+// a struct whose fields are mapped to the columns by the `db` tags, or a
+// pointer to a single value when the query returns only one column.
+func (r *Rows) Scan(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("connection: scan destination must be a non-nil pointer, got %T", dst)
+	}
+	if err := r.scanValue(v.Elem()); err != nil {
+		r.fail(err)
+		return err
+	}
+	return nil
+}
+
+func (r *Rows) scanValue(v reflect.Value) error {
+	if v.Kind() != reflect.Struct || isScanner(v.Type()) {
+		if len(r.columns) != 1 {
+			return fmt.Errorf("connection: scan %d columns into %s", len(r.columns), v.Type())
+		}
+		return r.rs.Scan(v.Addr().Interface())
+	}
+
+	info := getStructInfo(v.Type())
+	dest := make([]interface{}, len(r.columns))
+	for i, col := range r.columns {
+		field, ok := info.byColumn[strings.ToLower(col)]
+		if !ok {
+			return fmt.Errorf("%w: column %q has no field in %s", ErrUnmappedColumn, col, v.Type())
+		}
+		dest[i] = fieldByIndex(v, field.index).Addr().Interface()
+	}
+	if err := r.rs.Scan(dest...); err != nil {
+		return fmt.Errorf("connection: scan into %s: %w", v.Type(), err)
+	}
+	return nil
+}
+
+// isSliceDst reports whether dst is a pointer to slice.
+func isSliceDst(dst interface{}) bool {
+	t := reflect.TypeOf(dst)
+	return t != nil && t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Slice &&
+		t.Elem().Elem().Kind() != reflect.Uint8
+}
+
+// scanFirst scans the first row of the cursor into dst and closes it.
+func scanFirst(dst interface{}, rows *Rows, err error) error {
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return ErrNoRows
+	}
+	return rows.Scan(dst)
+}
+
+// scanAll scans all the rows of the cursor into dst and closes it. The dst is
+// a pointer to a slice of struct or pointer to struct.
+func scanAll(dst interface{}, rows *Rows, err error) error {
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("connection: scan destination must be a pointer to slice, got %T", dst)
+	}
+
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	slice.Set(slice.Slice(0, 0))
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := rows.scanValue(elem.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return rows.Err()
+}
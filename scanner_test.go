@@ -0,0 +1,199 @@
+package connection
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"ID", "id"},
+		{"Name", "name"},
+		{"UserID", "user_id"},
+		{"CreatedAt", "created_at"},
+		{"HTTPServer", "http_server"},
+		{"already_snake", "already_snake"},
+	}
+	for _, tt := range tests {
+		if got := snakeCase(tt.name); got != tt.want {
+			t.Errorf("snakeCase(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+type Audit struct {
+	CreatedAt time.Time `db:"created_at,readonly"`
+}
+
+type mappedUser struct {
+	ID      int64          `db:"id,pk"`
+	Name    string         `db:"name"`
+	Email   sql.NullString `db:"email,omitempty"`
+	Age     int
+	Ignored string `db:"-"`
+	secret  string
+	*Audit
+}
+
+func TestStructInfo(t *testing.T) {
+	info := getStructInfo(reflect.TypeOf(mappedUser{}))
+
+	tests := []struct {
+		column    string
+		index     []int
+		pk        bool
+		omitempty bool
+		readonly  bool
+	}{
+		{column: "id", index: []int{0}, pk: true},
+		{column: "name", index: []int{1}},
+		{column: "email", index: []int{2}, omitempty: true},
+		{column: "age", index: []int{3}},
+		{column: "created_at", index: []int{6, 0}, readonly: true},
+	}
+	if len(info.fields) != len(tests) {
+		t.Fatalf("got %d fields, want %d", len(info.fields), len(tests))
+	}
+	for i, tt := range tests {
+		f := info.fields[i]
+		if f.column != tt.column || !reflect.DeepEqual(f.index, tt.index) ||
+			f.pk != tt.pk || f.omitempty != tt.omitempty || f.readonly != tt.readonly {
+			t.Errorf("field %d = %+v, want %+v", i, f, tt)
+		}
+		if info.byColumn[tt.column] != &info.fields[i] {
+			t.Errorf("byColumn[%q] is not field %d", tt.column, i)
+		}
+	}
+}
+
+func TestStructValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    mappedUser
+		columns []string
+	}{
+		{
+			name:    "omit empty and readonly",
+			user:    mappedUser{ID: 1, Name: "a"},
+			columns: []string{"age", "id", "name"},
+		},
+		{
+			name:    "keep non empty",
+			user:    mappedUser{Name: "a", Email: sql.NullString{String: "a@b.c", Valid: true}},
+			columns: []string{"age", "email", "name"},
+		},
+		{
+			name:    "embedded readonly",
+			user:    mappedUser{ID: 1, Audit: &Audit{CreatedAt: time.Now()}},
+			columns: []string{"age", "id", "name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, pks := structValues(reflect.ValueOf(tt.user))
+			if got := sortedColumns(values); !reflect.DeepEqual(got, tt.columns) {
+				t.Errorf("columns = %v, want %v", got, tt.columns)
+			}
+			if len(pks) != 1 || pks[0].column != "id" {
+				t.Errorf("pks = %v, want [id]", pks)
+			}
+		})
+	}
+}
+
+// sortedColumns return the sorted columns of values.
+func sortedColumns(values map[string]interface{}) []string {
+	columns := make([]string, 0, len(values))
+	for col := range values {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func TestQueryInto(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn,
+		"create table users (id integer primary key, name text, email text, age integer, created_at datetime)",
+		"insert into users values (1, 'a', 'a@b.c', 20, '2020-01-02 03:04:05')",
+		"insert into users values (2, 'b', null, 30, '2020-02-03 04:05:06')",
+	)
+
+	t.Run("slice of struct", func(t *testing.T) {
+		var users []mappedUser
+		if err := conn.QueryInto(&users, "select * from users order by id"); err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 {
+			t.Fatalf("got %d users, want 2", len(users))
+		}
+		want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		if u := users[0]; u.ID != 1 || u.Name != "a" || u.Email.String != "a@b.c" || u.Age != 20 ||
+			u.Audit == nil || !u.CreatedAt.Equal(want) {
+			t.Errorf("users[0] = %+v", u)
+		}
+		if u := users[1]; u.ID != 2 || u.Email.Valid || u.Age != 30 {
+			t.Errorf("users[1] = %+v", u)
+		}
+	})
+
+	t.Run("slice of pointer", func(t *testing.T) {
+		var users []*mappedUser
+		if err := conn.QueryInto(&users, "select id, name from users order by id"); err != nil {
+			t.Fatal(err)
+		}
+		if len(users) != 2 || users[1].Name != "b" {
+			t.Errorf("users = %v", users)
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		var user mappedUser
+		if err := WithDriver(conn).Table("users").Where("id", "=", 2).FirstInto(&user); err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != 2 || user.Name != "b" {
+			t.Errorf("user = %+v", user)
+		}
+	})
+
+	t.Run("single value", func(t *testing.T) {
+		var names []string
+		if err := conn.QueryInto(&names, "select name from users order by id"); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(names, []string{"a", "b"}) {
+			t.Errorf("names = %v", names)
+		}
+	})
+
+	t.Run("no rows", func(t *testing.T) {
+		var user mappedUser
+		err := WithDriver(conn).Table("users").Where("id", "=", 3).FirstInto(&user)
+		if err != ErrNoRows {
+			t.Errorf("err = %v, want ErrNoRows", err)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		var users []mappedUser
+		err := conn.QueryInto(&users, "select id, 1 as extra from users")
+		if !errors.Is(err, ErrUnmappedColumn) {
+			t.Errorf("err = %v, want ErrUnmappedColumn", err)
+		}
+	})
+
+	t.Run("not a slice", func(t *testing.T) {
+		var user mappedUser
+		if err := conn.QueryInto(user, "select * from users"); err == nil {
+			t.Error("expect an error of the destination")
+		}
+	})
+}
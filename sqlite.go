@@ -1,8 +1,10 @@
 package connection
 
 import (
+	"context"
 	"database/sql"
 	"sync"
+	"time"
 )
 
 // Sqlite is a Connection of mssql.
@@ -27,96 +29,203 @@ func GetSqliteDB() *Sqlite {
 
 // QueryWithConnection implements the method Connection.QueryWithConnection.
 func (db *Sqlite) QueryWithConnection(con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList[con], query, args...)
+	return queryContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // ExecWithConnection implements the method Connection.ExecWithConnection.
 func (db *Sqlite) ExecWithConnection(con string, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList[con], query, args...)
+	return execContext(context.Background(), db.DbList[con], db.connOptions(con), query, args...)
 }
 
 // Query implements the method Connection.Query.
 func (db *Sqlite) Query(query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQuery(db.DbList["default"], query, args...)
+	return queryContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // Exec implements the method Connection.Exec.
 func (db *Sqlite) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return CommonExec(db.DbList["default"], query, args...)
+	return execContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
 }
 
 // InitDB implements the method Connection.InitDB.
-func (db *Sqlite) InitDB(cfgList map[string]Database) Connection {
+func (db *Sqlite) InitDB(cfgList map[string]Database) (Connection, error) {
 	db.Once.Do(func() {
 		for conn, cfg := range cfgList {
-			sqlDB, err := sql.Open("sqlite3", cfg.File)
-
+			sqlDB, err := db.open(cfg)
 			if err != nil {
-				panic(err)
-			} else {
-				db.DbList[conn] = sqlDB
+				db.initErr = err
+				return
+			}
+
+			db.DbList[conn] = sqlDB
+			db.initOptions(conn, sqlDB, cfg)
+
+			if err := db.initReplicas(db.DbList, conn, cfg, db.open); err != nil {
+				db.initErr = err
+				return
 			}
 		}
 	})
-	return db
+	return db, db.initErr
+}
+
+// open opens the database of cfg.
+func (db *Sqlite) open(cfg Database) (*sql.DB, error) {
+	return sql.Open("sqlite3", cfg.File)
 }
 
 // BeginTxWithReadUncommitted starts a transaction with level LevelReadUncommitted.
-func (db *Sqlite) BeginTxWithReadUncommitted() *sql.Tx {
+func (db *Sqlite) BeginTxWithReadUncommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommitted starts a transaction with level LevelReadCommitted.
-func (db *Sqlite) BeginTxWithReadCommitted() *sql.Tx {
+func (db *Sqlite) BeginTxWithReadCommitted() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableRead starts a transaction with level LevelRepeatableRead.
-func (db *Sqlite) BeginTxWithRepeatableRead() *sql.Tx {
+func (db *Sqlite) BeginTxWithRepeatableRead() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelRepeatableRead)
 }
 
 // BeginTx starts a transaction with level LevelDefault.
-func (db *Sqlite) BeginTx() *sql.Tx {
+func (db *Sqlite) BeginTx() (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], sql.LevelDefault)
 }
 
 // BeginTxWithLevel starts a transaction with given transaction isolation level.
-func (db *Sqlite) BeginTxWithLevel(level sql.IsolationLevel) *sql.Tx {
+func (db *Sqlite) BeginTxWithLevel(level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList["default"], level)
 }
 
 // BeginTxWithReadUncommittedAndConnection starts a transaction with level LevelReadUncommitted and connection.
-func (db *Sqlite) BeginTxWithReadUncommittedAndConnection(conn string) *sql.Tx {
+func (db *Sqlite) BeginTxWithReadUncommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadUncommitted)
 }
 
 // BeginTxWithReadCommittedAndConnection starts a transaction with level LevelReadCommitted and connection.
-func (db *Sqlite) BeginTxWithReadCommittedAndConnection(conn string) *sql.Tx {
+func (db *Sqlite) BeginTxWithReadCommittedAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelReadCommitted)
 }
 
 // BeginTxWithRepeatableReadAndConnection starts a transaction with level LevelRepeatableRead and connection.
-func (db *Sqlite) BeginTxWithRepeatableReadAndConnection(conn string) *sql.Tx {
+func (db *Sqlite) BeginTxWithRepeatableReadAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelRepeatableRead)
 }
 
 // BeginTxAndConnection starts a transaction with level LevelDefault and connection.
-func (db *Sqlite) BeginTxAndConnection(conn string) *sql.Tx {
+func (db *Sqlite) BeginTxAndConnection(conn string) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], sql.LevelDefault)
 }
 
 // BeginTxWithLevelAndConnection starts a transaction with given transaction isolation level and connection.
-func (db *Sqlite) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) *sql.Tx {
+func (db *Sqlite) BeginTxWithLevelAndConnection(conn string, level sql.IsolationLevel) (*sql.Tx, error) {
 	return CommonBeginTxWithLevel(db.DbList[conn], level)
 }
 
 // QueryWithTx is query method within the transaction.
 func (db *Sqlite) QueryWithTx(tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	return CommonQueryWithTx(tx, query, args...)
+	return queryWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
 }
 
 // ExecWithTx is exec method within the transaction.
 func (db *Sqlite) ExecWithTx(tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
-	return CommonExecWithTx(tx, query, args...)
+	return execWithTxContext(context.Background(), tx, db.txOptions(context.Background()), query, args...)
+}
+
+// QueryContext implements the method Connection.QueryContext.
+func (db *Sqlite) QueryContext(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// ExecContext implements the method Connection.ExecContext.
+func (db *Sqlite) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryWithConnectionContext implements the method Connection.QueryWithConnectionContext.
+func (db *Sqlite) QueryWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// ExecWithConnectionContext implements the method Connection.ExecWithConnectionContext.
+func (db *Sqlite) ExecWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// BeginTxContext starts a transaction with level LevelDefault and the given context.
+func (db *Sqlite) BeginTxContext(ctx context.Context) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], sql.LevelDefault)
+}
+
+// BeginTxWithLevelContext starts a transaction with given transaction isolation level and context.
+func (db *Sqlite) BeginTxWithLevelContext(ctx context.Context, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList["default"], level)
+}
+
+// BeginTxAndConnectionContext starts a transaction with level LevelDefault, connection and context.
+func (db *Sqlite) BeginTxAndConnectionContext(ctx context.Context, conn string) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], sql.LevelDefault)
+}
+
+// BeginTxWithLevelAndConnectionContext starts a transaction with given transaction isolation level, connection and context.
+func (db *Sqlite) BeginTxWithLevelAndConnectionContext(ctx context.Context, conn string, level sql.IsolationLevel) (*sql.Tx, error) {
+	return CommonBeginTxWithLevelContext(ctx, db.DbList[conn], level)
+}
+
+// QueryWithTxContext is query method within the transaction with the given context.
+func (db *Sqlite) QueryWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	return queryWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// ExecWithTxContext is exec method within the transaction with the given context.
+func (db *Sqlite) ExecWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	return execWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryRows implements the method Connection.QueryRows.
+func (db *Sqlite) QueryRows(query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(context.Background(), db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsContext implements the method Connection.QueryRowsContext.
+func (db *Sqlite) QueryRowsContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList["default"], db.connOptions("default"), query, args...)
+}
+
+// QueryRowsWithConnectionContext implements the method Connection.QueryRowsWithConnectionContext.
+func (db *Sqlite) QueryRowsWithConnectionContext(ctx context.Context, con string, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsContext(ctx, db.DbList[con], db.connOptions(con), query, args...)
+}
+
+// QueryRowsWithTxContext is query method within the transaction with the given context
+// which returns a cursor of the results.
+func (db *Sqlite) QueryRowsWithTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*Rows, error) {
+	return queryRowsWithTxContext(ctx, tx, db.txOptions(ctx), query, args...)
+}
+
+// QueryInto implements the method Connection.QueryInto.
+func (db *Sqlite) QueryInto(dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(context.Background(), db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// QueryIntoContext implements the method Connection.QueryIntoContext.
+func (db *Sqlite) QueryIntoContext(ctx context.Context, dst interface{}, query string, args ...interface{}) error {
+	return queryIntoContext(ctx, db.DbList["default"], db.connOptions("default"), dst, query, args...)
+}
+
+// Ping implements the method Connection.Ping.
+func (db *Sqlite) Ping(ctx context.Context, con string) error {
+	return CommonPing(ctx, db.DbList[con])
+}
+
+// HealthCheck implements the method HealthChecker.HealthCheck.
+func (db *Sqlite) HealthCheck() map[string]HealthStatus {
+	return db.healthCheck(db.DbList)
+}
+
+// StartHealthCheck implements the method HealthChecker.StartHealthCheck.
+func (db *Sqlite) StartHealthCheck(interval time.Duration) {
+	db.startHealthCheck(db.DbList, interval)
 }
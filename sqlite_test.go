@@ -0,0 +1,123 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/chenhg5/go-sql/dialect"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// openSqlite opens the in-memory sqlite database of cfg as the default
+// connection, which is limited to one connection to keep the data.
+func openSqlite(t testing.TB, cfg Database) *Sqlite {
+	t.Helper()
+	if cfg.File == "" {
+		cfg.File = ":memory:"
+	}
+	conn := GetSqliteDB()
+	if _, err := conn.InitDB(map[string]Database{"default": cfg}); err != nil {
+		t.Fatal(err)
+	}
+	conn.DbList["default"].SetMaxOpenConns(1)
+	return conn
+}
+
+// mustExec executes the statements on the default connection.
+func mustExec(t testing.TB, conn Connection, statements ...string) {
+	t.Helper()
+	for _, statement := range statements {
+		if _, err := conn.Exec(statement); err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+}
+
+func TestSqliteUpsert(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn, "create table users (id integer primary key, name text, age integer)")
+
+	for _, values := range []dialect.H{
+		{"id": 1, "name": "a", "age": 20},
+		{"id": 1, "name": "b", "age": 30},
+	} {
+		if _, err := WithDriver(conn).Table("users").Upsert(values, []string{"id"}, []string{"name"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := conn.Query("select name, age from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0]["name"] != "b" || res[0]["age"] != int64(20) {
+		t.Errorf("rows = %v", res)
+	}
+}
+
+func TestSqliteCanceledContext(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn, "create table users (id integer primary key, name text)")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"QueryContext", func() error {
+			_, err := conn.QueryContext(ctx, "select * from users")
+			return err
+		}},
+		{"ExecContext", func() error {
+			_, err := conn.ExecContext(ctx, "insert into users (name) values (?)", "a")
+			return err
+		}},
+		{"QueryRowsContext", func() error {
+			_, err := conn.QueryRowsContext(ctx, "select * from users")
+			return err
+		}},
+		{"QueryIntoContext", func() error {
+			var names []string
+			return conn.QueryIntoContext(ctx, &names, "select name from users")
+		}},
+		{"BeginTxContext", func() error {
+			_, err := conn.BeginTxContext(ctx)
+			return err
+		}},
+		{"builder All", func() error {
+			_, err := WithDriver(conn).WithContext(ctx).Table("users").All()
+			return err
+		}},
+		{"builder Insert", func() error {
+			_, err := WithDriver(conn).WithContext(ctx).Table("users").Insert(dialect.H{"name": "a"})
+			return err
+		}},
+		{"builder WithTransaction", func() error {
+			_, err := WithDriver(conn).WithContext(ctx).WithTransaction(func(tx *sql.Tx) (error, map[string]interface{}) {
+				return nil, nil
+			})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		if err := tt.fn(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: err = %v, want context.Canceled", tt.name, err)
+		}
+	}
+
+	tx, err := conn.BeginTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := conn.QueryWithTxContext(ctx, tx, "select * from users"); !errors.Is(err, context.Canceled) {
+		t.Errorf("QueryWithTxContext: err = %v, want context.Canceled", err)
+	}
+	if _, err := conn.QueryWithTx(tx, "select * from users"); err != nil {
+		t.Errorf("QueryWithTx after a canceled query: %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package connection
 
 import (
+	"context"
 	dbsql "database/sql"
-	"errors"
 	"fmt"
 	"github.com/chenhg5/go-sql/dialect"
+	"math/big"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,6 +20,10 @@ type SQL struct {
 	dialect dialect.Dialect
 	conn    string
 	tx      *dbsql.Tx
+	ctx     context.Context
+	errs    Errors
+	// primary is whether the reads are not routed to the replicas.
+	primary bool
 }
 
 // SQLPool is a object pool of SQL.
@@ -25,16 +31,16 @@ var SQLPool = sync.Pool{
 	New: func() interface{} {
 		return &SQL{
 			SQLComponent: dialect.SQLComponent{
-				Fields:     make([]string, 0),
+				Fields:     make([]dialect.Field, 0),
 				TableName:  "",
 				Args:       make([]interface{}, 0),
 				Wheres:     make([]dialect.Where, 0),
-				Leftjoins:  make([]dialect.Join, 0),
+				Joins:      make([]dialect.Join, 0),
 				UpdateRaws: make([]dialect.RawUpdate, 0),
-				WhereRaws:  "",
 			},
 			diver:   nil,
 			dialect: nil,
+			ctx:     context.Background(),
 		}
 	},
 }
@@ -84,85 +90,361 @@ func (sql *SQL) WithDriver(conn Connection) *SQL {
 	return sql
 }
 
+// WithDialect set the dialect of SQL, which renders the statements of
+// ToSQL and so on without a driver.
+func (sql *SQL) WithDialect(d dialect.Dialect) *SQL {
+	sql.dialect = d
+	return sql
+}
+
 // WithConnection set the connection name of SQL.
 func (sql *SQL) WithConnection(conn string) *SQL {
 	sql.conn = conn
 	return sql
 }
 
-// WithTx set the database transaction object of SQL.
+// WithTx set the database transaction object of SQL. The transaction must be
+// started on the connection of SQL, which is given by WithConnection if it is
+// not the default one, so that the options of it are applied.
 func (sql *SQL) WithTx(tx *dbsql.Tx) *SQL {
 	sql.tx = tx
 	return sql
 }
 
+// UsePrimary makes the reads of SQL go to the primary instead of the replicas
+// of the connection, e.g. to read the rows just written, which the replicas
+// may not have yet. The reads of all the SQL go to the primary for the
+// Database.StickyPrimary duration after a write of the SQL builder, so it is
+// only needed when the write is older or done without the builder.
+func (sql *SQL) UsePrimary() *SQL {
+	sql.primary = true
+	return sql
+}
+
+// readConnection return the connection which the reads of SQL are routed to.
+func (sql *SQL) readConnection() string {
+	router, ok := sql.diver.(ReplicaRouter)
+	if sql.primary || !ok {
+		return sql.conn
+	}
+	return router.ReadConnection(sql.conn)
+}
+
+// exec executes the rendered statement within the transaction of SQL or on
+// the connection of it.
+func (sql *SQL) exec() (dbsql.Result, error) {
+	var (
+		res dbsql.Result
+		err error
+	)
+
+	if sql.tx != nil {
+		res, err = sql.diver.ExecWithTxContext(sql.txContext(), sql.tx, sql.Statement, sql.Args...)
+	} else {
+		res, err = sql.diver.ExecWithConnectionContext(sql.ctx, sql.conn, sql.Statement, sql.Args...)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sql.written()
+	return res, nil
+}
+
+// written tells the driver that the connection of SQL is written, so that the
+// reads of it stick to the primary for a while.
+func (sql *SQL) written() {
+	if router, ok := sql.diver.(ReplicaRouter); ok {
+		router.Written(sql.conn)
+	}
+}
+
+// txContext return the context of the queries within the transaction, which
+// carries the connection name for the options of it.
+func (sql *SQL) txContext() context.Context {
+	return TxContext(sql.ctx, sql.conn)
+}
+
+// WithContext set the context of SQL which is used by the terminal methods,
+// so that the cancellation and deadline are propagated into the database.
+func (sql *SQL) WithContext(ctx context.Context) *SQL {
+	sql.ctx = ctx
+	return sql
+}
+
+// Err return the errors accumulated while building the SQL, or nil if there is
+// none. The terminal methods return it without touching the database.
+func (sql *SQL) Err() error {
+	switch len(sql.errs) {
+	case 0:
+		return nil
+	case 1:
+		return sql.errs[0]
+	default:
+		return sql.errs
+	}
+}
+
+func (sql *SQL) addError(err error) *SQL {
+	sql.errs = append(sql.errs, err)
+	return sql
+}
+
+// check return the error which prevents the SQL from being executed.
+func (sql *SQL) check() error {
+	if err := sql.Err(); err != nil {
+		return err
+	}
+	if sql.diver == nil || sql.dialect == nil {
+		return ErrDriverNotFound
+	}
+	return nil
+}
+
 // TableName set table of SQL.
 func (sql *SQL) Table(table string) *SQL {
 	sql.TableName = table
 	return sql
 }
 
-// Select set select fields.
+// Select set select fields. A field is a column, the function form
+// "fn(field)" or an expression, followed by an optional alias, e.g.
+// "max(id) as m" or "sum(price) * 2 as total".
 func (sql *SQL) Select(fields ...string) *SQL {
-	sql.Fields = fields
-	sql.Functions = make([]string, len(fields))
-	reg, _ := regexp.Compile("(.*?)\\((.*?)\\)")
+	sql.Fields = make([]dialect.Field, len(fields))
 	for k, field := range fields {
-		res := reg.FindAllStringSubmatch(field, -1)
-		if len(res) > 0 && len(res[0]) > 2 {
-			sql.Functions[k] = res[0][1]
-			sql.Fields[k] = res[0][2]
-		}
+		sql.Fields[k] = parseField(field)
 	}
 	return sql
 }
 
-// OrderBy set order fields.
+var (
+	fieldFunctionReg = regexp.MustCompile(`^(\w+)\(([^()]*)\)$`)
+	fieldAliasReg    = regexp.MustCompile(`(?i)^(.+?)\s+as\s+(\w+)$`)
+	fieldColumnReg   = regexp.MustCompile(`^(\*|(\w+\s+)?[\w.]+(\.\*)?(\s+\w+)?)$`)
+)
+
+// parseField parse the select field, which is a column, the function form
+// "fn(field)" or an expression, followed by an optional "as alias". The
+// expressions other than the single function are selected as they are.
+func parseField(field string) dialect.Field {
+	field = strings.TrimSpace(field)
+	alias := ""
+	if res := fieldAliasReg.FindStringSubmatch(field); res != nil {
+		field, alias = strings.TrimSpace(res[1]), res[2]
+	}
+	if res := fieldFunctionReg.FindStringSubmatch(field); res != nil {
+		return dialect.Field{Function: res[1], Name: strings.TrimSpace(res[2]), Alias: alias}
+	}
+	if fieldColumnReg.MatchString(field) {
+		return dialect.Field{Name: field, Alias: alias}
+	}
+	return dialect.Field{Raw: field, Alias: alias}
+}
+
+// SelectAs add the select field with given alias. The field can be the
+// function form "fn(field)" as Select.
+func (sql *SQL) SelectAs(field string, alias string) *SQL {
+	f := parseField(field)
+	f.Alias = alias
+	sql.Fields = append(sql.Fields, f)
+	return sql
+}
+
+// SelectRaw add the raw expression as a select field, e.g.
+// "count(distinct user_id) as users" or "price * ? as total", with the
+// arguments of it.
+func (sql *SQL) SelectRaw(expr string, args ...interface{}) *SQL {
+	sql.Fields = append(sql.Fields, dialect.Field{
+		Raw:  expr,
+		Args: args,
+	})
+	return sql
+}
+
+// SelectSub add the subquery as a select field with given alias, which is
+// rendered with the dialect of SQL.
+func (sql *SQL) SelectSub(sub *SQL, alias string) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.Fields = append(sql.Fields, dialect.Field{
+		Sub:   &sub.SQLComponent,
+		Alias: alias,
+	})
+	return sql
+}
+
+// FromSub set the subquery as the table of SQL with given alias.
+func (sql *SQL) FromSub(sub *SQL, alias string) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.From = &sub.SQLComponent
+	sql.TableName = alias
+	return sql
+}
+
+// OrderBy add order by fields. A direction "asc" or "desc", and "nulls first"
+// or "nulls last", at the end of a field are applied to it, or to the former
+// field if they are given alone. The rest of the field is kept as a whole, so
+// that it can be an expression. For example:
+//
+//	sql.OrderBy("score", "desc", "id")
+//	sql.OrderBy("score desc nulls last", "id asc")
+//	sql.OrderBy("field(id, 3, 1)")
 func (sql *SQL) OrderBy(fields ...string) *SQL {
-	if len(fields) == 0 {
-		panic("wrong order field")
+	start := len(sql.Orders)
+	for _, item := range fields {
+		field, direction, nulls, err := splitOrder(item)
+		if err != nil {
+			return sql.addError(err)
+		}
+		if field == "" {
+			last := len(sql.Orders) - 1
+			if last < start || direction == "" && nulls == "" {
+				return sql.addError(fmt.Errorf("%w: %q without field", ErrEmptyOrder, item))
+			}
+			if direction != "" {
+				sql.Orders[last].Direction = direction
+			}
+			if nulls != "" {
+				sql.Orders[last].Nulls = nulls
+			}
+			continue
+		}
+		sql.Orders = append(sql.Orders, dialect.Order{Column: field, Direction: direction, Nulls: nulls})
+	}
+	if len(sql.Orders) == start {
+		return sql.addError(ErrEmptyOrder)
 	}
-	for i := 0; i < len(fields); i++ {
-		if i == len(fields)-2 {
-			sql.Order += " " + sql.wrap(fields[i]) + " " + fields[i+1]
-			return sql
+	return sql
+}
+
+// splitOrder splits the trailing "asc" or "desc" and "nulls first" or "nulls
+// last" from the order by item.
+func splitOrder(item string) (field, direction, nulls string, err error) {
+	field = strings.TrimSpace(item)
+	if rest, word := cutLastWord(field); word == "first" || word == "last" {
+		if rest, prev := cutLastWord(rest); prev == "nulls" {
+			field, nulls = rest, word
 		}
-		sql.Order += " " + sql.wrap(fields[i]) + " and "
+	} else if word == "nulls" {
+		return "", "", "", fmt.Errorf("%w: invalid nulls order", ErrEmptyOrder)
+	}
+	if rest, word := cutLastWord(field); word == "asc" || word == "desc" {
+		field, direction = rest, word
+	}
+	return field, direction, nulls, nil
+}
+
+// cutLastWord cuts the last word of s, which is returned in lower case.
+func cutLastWord(s string) (rest, word string) {
+	i := strings.LastIndexAny(s, " \t\r\n")
+	return strings.TrimSpace(s[:i+1]), strings.ToLower(s[i+1:])
+}
+
+// OrderByAsc add order by fields in ascending order.
+func (sql *SQL) OrderByAsc(fields ...string) *SQL {
+	return sql.orderBy("asc", fields)
+}
+
+// OrderByDesc add order by fields in descending order.
+func (sql *SQL) OrderByDesc(fields ...string) *SQL {
+	return sql.orderBy("desc", fields)
+}
+
+func (sql *SQL) orderBy(direction string, fields []string) *SQL {
+	if len(fields) == 0 {
+		return sql.addError(ErrEmptyOrder)
+	}
+	for _, field := range fields {
+		sql.Orders = append(sql.Orders, dialect.Order{Column: field, Direction: direction})
+	}
+	return sql
+}
+
+// Latest order by the given field, or "created_at" if absent, in descending order.
+func (sql *SQL) Latest(field ...string) *SQL {
+	if len(field) == 0 {
+		return sql.OrderByDesc("created_at")
+	}
+	return sql.OrderByDesc(field...)
+}
+
+// Oldest order by the given field, or "created_at" if absent, in ascending order.
+func (sql *SQL) Oldest(field ...string) *SQL {
+	if len(field) == 0 {
+		return sql.OrderByAsc("created_at")
 	}
+	return sql.OrderByAsc(field...)
+}
+
+// InRandomOrder order the rows randomly by the random function of dialect.
+func (sql *SQL) InRandomOrder() *SQL {
+	sql.Orders = append(sql.Orders, dialect.Order{Random: true})
 	return sql
 }
 
-// OrderByRaw set order by.
+// OrderByRaw add the raw order by expression.
 func (sql *SQL) OrderByRaw(order string) *SQL {
 	if order != "" {
-		sql.Order += " " + order
+		sql.Orders = append(sql.Orders, dialect.Order{Raw: order})
 	}
 	return sql
 }
 
-// GroupBy set group by fields.
+// GroupBy add group by fields.
 func (sql *SQL) GroupBy(fields ...string) *SQL {
 	if len(fields) == 0 {
-		panic("wrong group by field")
+		return sql.addError(ErrEmptyGroup)
 	}
-	for i := 0; i < len(fields); i++ {
-		if i == len(fields)-2 {
-			sql.Group += " " + sql.wrap(fields[i]) + " " + fields[i+1]
-			return sql
-		}
-		sql.Group += " " + sql.wrap(fields[i]) + " and "
+	for _, field := range fields {
+		sql.Groups = append(sql.Groups, dialect.Group{Column: field})
 	}
 	return sql
 }
 
-// GroupByRaw set group by.
+// GroupByRaw add the raw group by expression.
 func (sql *SQL) GroupByRaw(group string) *SQL {
 	if group != "" {
-		sql.Group += " " + group
+		sql.Groups = append(sql.Groups, dialect.Group{Raw: group})
 	}
 	return sql
 }
 
+// Having add the having operation and argument value. The field can be an
+// aggregate expression such as "count(id)". An alias of select field is only
+// accepted by mysql and sqlite, and the expression must be repeated in the
+// others.
+func (sql *SQL) Having(field string, operation string, arg interface{}) *SQL {
+	sql.Havings = append(sql.Havings, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{arg},
+	})
+	return sql
+}
+
+// OrHaving add the having operation and argument value joined by "or".
+func (sql *SQL) OrHaving(field string, operation string, arg interface{}) *SQL {
+	sql.Havings = append(sql.Havings, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{arg},
+		Or:        true,
+	})
+	return sql
+}
+
+// HavingRaw add a raw having expression and the arguments of it.
+func (sql *SQL) HavingRaw(raw string, args ...interface{}) *SQL {
+	sql.Havings = append(sql.Havings, dialect.Where{
+		Raw:  raw,
+		Args: args,
+	})
+	return sql
+}
+
 // Skip set offset value.
 func (sql *SQL) Skip(offset int) *SQL {
 	sql.Offset = strconv.Itoa(offset)
@@ -181,36 +463,187 @@ func (sql *SQL) Where(field string, operation string, arg interface{}) *SQL {
 		Field:     field,
 		Operation: operation,
 		Qmark:     "?",
+		Args:      []interface{}{arg},
 	})
-	sql.Args = append(sql.Args, arg)
 	return sql
 }
 
-// WhereIn add the where operation of "in" and argument values.
-func (sql *SQL) WhereIn(field string, arg []interface{}) *SQL {
-	if len(arg) == 0 {
-		panic("wrong parameter")
-	}
+// OrWhere add the where operation and argument value joined by "or".
+func (sql *SQL) OrWhere(field string, operation string, arg interface{}) *SQL {
 	sql.Wheres = append(sql.Wheres, dialect.Where{
 		Field:     field,
-		Operation: "in",
-		Qmark:     "(" + strings.Repeat("?,", len(arg)-1) + "?)",
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{arg},
+		Or:        true,
 	})
-	sql.Args = append(sql.Args, arg...)
 	return sql
 }
 
-// WhereNotIn add the where operation of "not in" and argument values.
-func (sql *SQL) WhereNotIn(field string, arg []interface{}) *SQL {
-	if len(arg) == 0 {
-		panic("wrong parameter")
+// WhereIn add the where operation of "in" and argument values. The arg is a
+// slice of values, or a *SQL which is rendered as the subquery.
+func (sql *SQL) WhereIn(field string, arg interface{}) *SQL {
+	return sql.whereIn(field, "in", arg)
+}
+
+// WhereNotIn add the where operation of "not in" and argument values. The arg
+// is a slice of values, or a *SQL which is rendered as the subquery.
+func (sql *SQL) WhereNotIn(field string, arg interface{}) *SQL {
+	return sql.whereIn(field, "not in", arg)
+}
+
+func (sql *SQL) whereIn(field string, operation string, arg interface{}) *SQL {
+	if sub, ok := arg.(*SQL); ok {
+		return sql.whereSub(field, operation, sub)
+	}
+
+	args, ok := arg.([]interface{})
+	if !ok && arg != nil {
+		v := reflect.ValueOf(arg)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return sql.addError(fmt.Errorf("wrong argument type %T of %s operation", arg, operation))
+		}
+		args = make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			args[i] = v.Index(i).Interface()
+		}
+	}
+
+	if len(args) == 0 {
+		return sql.addError(ErrEmptyIn)
 	}
 	sql.Wheres = append(sql.Wheres, dialect.Where{
 		Field:     field,
-		Operation: "not in",
-		Qmark:     "(" + strings.Repeat("?,", len(arg)-1) + "?)",
+		Operation: operation,
+		Qmark:     "(" + strings.Repeat("?,", len(args)-1) + "?)",
+		Args:      args,
+	})
+	return sql
+}
+
+// WhereNull add the where operation of "is null".
+func (sql *SQL) WhereNull(field string) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: "is null",
+	})
+	return sql
+}
+
+// WhereNotNull add the where operation of "is not null".
+func (sql *SQL) WhereNotNull(field string) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: "is not null",
+	})
+	return sql
+}
+
+// WhereBetween add the where operation of "between" and the range values.
+func (sql *SQL) WhereBetween(field string, from, to interface{}) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: "between",
+		Qmark:     "? and ?",
+		Args:      []interface{}{from, to},
+	})
+	return sql
+}
+
+// WhereNotBetween add the where operation of "not between" and the range values.
+func (sql *SQL) WhereNotBetween(field string, from, to interface{}) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: "not between",
+		Qmark:     "? and ?",
+		Args:      []interface{}{from, to},
+	})
+	return sql
+}
+
+// WhereLike add the where operation of "like" and the pattern, in which the
+// wildcard characters are escaped by backslash on every dialect. Use
+// dialect.EscapeLike to match the user input literally.
+func (sql *SQL) WhereLike(field string, pattern string) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: "like",
+		Qmark:     "?",
+		Args:      []interface{}{pattern},
+		Escape:    true,
+	})
+	return sql
+}
+
+// WhereColumn add the where operation which compares two columns.
+func (sql *SQL) WhereColumn(fieldA string, operation string, fieldB string) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     fieldA,
+		Operation: operation,
+		Column:    fieldB,
+	})
+	return sql
+}
+
+// WhereExists add the where operation of "exists" and the subquery, which is
+// rendered with the dialect of SQL.
+func (sql *SQL) WhereExists(sub *SQL) *SQL {
+	return sql.whereSub("", "exists", sub)
+}
+
+// WhereNotExists add the where operation of "not exists" and the subquery.
+func (sql *SQL) WhereNotExists(sub *SQL) *SQL {
+	return sql.whereSub("", "not exists", sub)
+}
+
+func (sql *SQL) whereSub(field, operation string, sub *SQL) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Sub:       &sub.SQLComponent,
+	})
+	return sql
+}
+
+// WhereDate add the where operation which compares the date part of field.
+func (sql *SQL) WhereDate(field string, operation string, date interface{}) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{date},
+		Date:      true,
 	})
-	sql.Args = append(sql.Args, arg...)
+	return sql
+}
+
+// WhereGroup add a group of where operations wrapped in parentheses, which
+// are added by the callback function.
+//
+//	// where (`a` = ? or `b` = ?) and `c` = ?
+//	sql.WhereGroup(func(group *SQL) {
+//		group.Where("a", "=", 1).OrWhere("b", "=", 2)
+//	}).Where("c", "=", 3)
+func (sql *SQL) WhereGroup(fn func(group *SQL)) *SQL {
+	return sql.whereGroup(fn, false)
+}
+
+// OrWhereGroup add a group of where operations wrapped in parentheses joined by "or".
+func (sql *SQL) OrWhereGroup(fn func(group *SQL)) *SQL {
+	return sql.whereGroup(fn, true)
+}
+
+func (sql *SQL) whereGroup(fn func(group *SQL), or bool) *SQL {
+	group := &SQL{diver: sql.diver, dialect: sql.dialect}
+	fn(group)
+	sql.errs = append(sql.errs, group.errs...)
+	if len(group.Wheres) > 0 {
+		sql.Wheres = append(sql.Wheres, dialect.Where{
+			Nested: group.Wheres,
+			Or:     or,
+		})
+	}
 	return sql
 }
 
@@ -219,119 +652,376 @@ func (sql *SQL) Find(arg interface{}) (map[string]interface{}, error) {
 	return sql.Where("id", "=", arg).First()
 }
 
+// aggregateAlias is the alias of the aggregate result selected by Count, Sum,
+// Max, Min and Avg.
+const aggregateAlias = "aggregate"
+
+// aggregate query the result of given aggregate function of field.
+func (sql *SQL) aggregate(function string, field string) (interface{}, error) {
+	sql.Fields = []dialect.Field{{Function: function, Name: field, Alias: aggregateAlias}}
+	res, err := sql.First()
+	if err != nil {
+		return nil, err
+	}
+	return res[aggregateAlias], nil
+}
+
 // Count query the count of query results.
 func (sql *SQL) Count() (int64, error) {
-	var (
-		res map[string]interface{}
-		err error
-	)
-	if res, err = sql.Select("count(*)").First(); err != nil {
+	res, err := sql.aggregate("count", "*")
+	if err != nil {
 		return 0, err
 	}
-	return res["count(*)"].(int64), nil
+	switch r := res.(type) {
+	case int64:
+		return r, nil
+	case []uint8:
+		return strconv.ParseInt(string(r), 10, 64)
+	default:
+		return 0, fmt.Errorf("connection: unexpected count result %T", res)
+	}
 }
 
-// Sum sum the value of given field.
+// Sum sum the value of given field. It returns 0 if there is no row.
 func (sql *SQL) Sum(field string) (float64, error) {
-	var (
-		res map[string]interface{}
-		err error
-	)
-	if res, err = sql.Select("sum(" + field + ")").First(); err != nil {
+	res, err := sql.aggregate("sum", field)
+	if err != nil {
 		return 0, err
 	}
-
-	if res == nil {
+	switch r := res.(type) {
+	case nil:
 		return 0, nil
-	}
-
-	if r, ok := res["sum("+sql.wrap(field)+")"].(float64); ok {
+	case float64:
 		return r, nil
-	} else if r, ok := res["sum("+sql.wrap(field)+")"].([]uint8); ok {
+	case int64:
+		return float64(r), nil
+	case []uint8:
 		return strconv.ParseFloat(string(r), 64)
-	} else {
-		return 0, nil
+	case string:
+		// the decimal converted by DecimalStringConverter
+		return strconv.ParseFloat(r, 64)
+	case *big.Rat:
+		// the decimal converted by DecimalRatConverter
+		f, _ := r.Float64()
+		return f, nil
+	default:
+		return 0, fmt.Errorf("connection: unexpected sum result %T", res)
 	}
 }
 
 // Max find the maximal value of given field.
 func (sql *SQL) Max(field string) (interface{}, error) {
-	var (
-		res map[string]interface{}
-		err error
-	)
-	if res, err = sql.Select("max(" + field + ")").First(); err != nil {
-		return 0, err
-	}
+	return sql.aggregate("max", field)
+}
+
+// Min find the minimal value of given field.
+func (sql *SQL) Min(field string) (interface{}, error) {
+	return sql.aggregate("min", field)
+}
+
+// Avg find the average value of given field.
+func (sql *SQL) Avg(field string) (interface{}, error) {
+	return sql.aggregate("avg", field)
+}
+
+// WhereRaw add a raw where expression and the arguments of it. It can be
+// called multiple times, and the expressions are joined by "and".
+func (sql *SQL) WhereRaw(raw string, args ...interface{}) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Raw:  raw,
+		Args: args,
+	})
+	return sql
+}
+
+// OrWhereRaw add a raw where expression and the arguments of it joined by "or".
+func (sql *SQL) OrWhereRaw(raw string, args ...interface{}) *SQL {
+	sql.Wheres = append(sql.Wheres, dialect.Where{
+		Raw:  raw,
+		Args: args,
+		Or:   true,
+	})
+	return sql
+}
+
+// UpdateRaw set UpdateRaw.
+func (sql *SQL) UpdateRaw(raw string, args ...interface{}) *SQL {
+	sql.UpdateRaws = append(sql.UpdateRaws, dialect.RawUpdate{
+		Expression: raw,
+		Args:       args,
+	})
+	return sql
+}
+
+// Join add an inner join info.
+func (sql *SQL) Join(table string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.join("inner", table, fieldA, operation, fieldB)
+}
+
+// LeftJoin add a left join info.
+func (sql *SQL) LeftJoin(table string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.join("left", table, fieldA, operation, fieldB)
+}
+
+// RightJoin add a right join info.
+func (sql *SQL) RightJoin(table string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.join("right", table, fieldA, operation, fieldB)
+}
+
+// FullJoin add a full join info. The terminal methods return an error wrapping
+// dialect.ErrUnsupported on the drivers which don't support it, e.g. mysql and sqlite.
+func (sql *SQL) FullJoin(table string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.join("full", table, fieldA, operation, fieldB)
+}
+
+// CrossJoin add a cross join info.
+func (sql *SQL) CrossJoin(table string) *SQL {
+	sql.Joins = append(sql.Joins, dialect.Join{
+		Type:  "cross",
+		Table: table,
+	})
+	return sql
+}
+
+func (sql *SQL) join(typ string, table string, fieldA string, operation string, fieldB string) *SQL {
+	sql.Joins = append(sql.Joins, dialect.Join{
+		Type:      typ,
+		FieldA:    fieldA,
+		FieldB:    fieldB,
+		Table:     table,
+		Operation: operation,
+	})
+	return sql
+}
+
+// JoinSub add an inner join info of the subquery with given alias.
+func (sql *SQL) JoinSub(sub *SQL, alias string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.joinSub("inner", sub, alias, fieldA, operation, fieldB)
+}
+
+// LeftJoinSub add a left join info of the subquery with given alias.
+func (sql *SQL) LeftJoinSub(sub *SQL, alias string, fieldA string, operation string, fieldB string) *SQL {
+	return sql.joinSub("left", sub, alias, fieldA, operation, fieldB)
+}
+
+func (sql *SQL) joinSub(typ string, sub *SQL, alias string, fieldA string, operation string, fieldB string) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.Joins = append(sql.Joins, dialect.Join{
+		Type:      typ,
+		FieldA:    fieldA,
+		FieldB:    fieldB,
+		Table:     alias,
+		Operation: operation,
+		Sub:       &sub.SQLComponent,
+	})
+	return sql
+}
+
+// JoinOn add an inner join info with the on conditions added by the callback function.
+//
+//	// inner join `roles` on users.role_id = roles.id and `roles`.`enabled` = ?
+//	sql.JoinOn("roles", func(join *JoinClause) {
+//		join.On("users.role_id", "=", "roles.id").Where("roles.enabled", "=", 1)
+//	})
+func (sql *SQL) JoinOn(table string, fn func(join *JoinClause)) *SQL {
+	return sql.joinOn("inner", table, fn)
+}
+
+// LeftJoinOn add a left join info with the on conditions added by the callback function.
+func (sql *SQL) LeftJoinOn(table string, fn func(join *JoinClause)) *SQL {
+	return sql.joinOn("left", table, fn)
+}
+
+// RightJoinOn add a right join info with the on conditions added by the callback function.
+func (sql *SQL) RightJoinOn(table string, fn func(join *JoinClause)) *SQL {
+	return sql.joinOn("right", table, fn)
+}
+
+// FullJoinOn add a full join info with the on conditions added by the callback function.
+func (sql *SQL) FullJoinOn(table string, fn func(join *JoinClause)) *SQL {
+	return sql.joinOn("full", table, fn)
+}
 
-	if res == nil {
-		return 0, nil
-	}
+func (sql *SQL) joinOn(typ string, table string, fn func(join *JoinClause)) *SQL {
+	join := &JoinClause{}
+	fn(join)
+	sql.Joins = append(sql.Joins, dialect.Join{
+		Type:       typ,
+		Table:      table,
+		Conditions: join.conditions,
+	})
+	return sql
+}
 
-	return res["max("+sql.wrap(field)+")"], nil
+// JoinClause is the on conditions of a join.
+type JoinClause struct {
+	conditions []dialect.Where
 }
 
-// Min find the minimal value of given field.
-func (sql *SQL) Min(field string) (interface{}, error) {
-	var (
-		res map[string]interface{}
-		err error
-	)
-	if res, err = sql.Select("min(" + field + ")").First(); err != nil {
-		return 0, err
-	}
+// On add the condition which compares two columns.
+func (join *JoinClause) On(fieldA string, operation string, fieldB string) *JoinClause {
+	join.conditions = append(join.conditions, dialect.Where{
+		Field:     fieldA,
+		Operation: operation,
+		Column:    fieldB,
+	})
+	return join
+}
 
-	if res == nil {
-		return 0, nil
-	}
+// OrOn add the condition which compares two columns joined by "or".
+func (join *JoinClause) OrOn(fieldA string, operation string, fieldB string) *JoinClause {
+	join.conditions = append(join.conditions, dialect.Where{
+		Field:     fieldA,
+		Operation: operation,
+		Column:    fieldB,
+		Or:        true,
+	})
+	return join
+}
 
-	return res["min("+sql.wrap(field)+")"], nil
+// Where add the condition which compares the column with the argument value.
+func (join *JoinClause) Where(field string, operation string, arg interface{}) *JoinClause {
+	join.conditions = append(join.conditions, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{arg},
+	})
+	return join
 }
 
-// Avg find the average value of given field.
-func (sql *SQL) Avg(field string) (interface{}, error) {
-	var (
-		res map[string]interface{}
-		err error
-	)
-	if res, err = sql.Select("avg(" + field + ")").First(); err != nil {
-		return 0, err
-	}
+// OrWhere add the condition which compares the column with the argument value joined by "or".
+func (join *JoinClause) OrWhere(field string, operation string, arg interface{}) *JoinClause {
+	join.conditions = append(join.conditions, dialect.Where{
+		Field:     field,
+		Operation: operation,
+		Qmark:     "?",
+		Args:      []interface{}{arg},
+		Or:        true,
+	})
+	return join
+}
 
-	if res == nil {
-		return 0, nil
-	}
+// Union combine the results of the subquery by "union", which removes the
+// duplicate rows. The order and limit of SQL are applied to the combined results.
+func (sql *SQL) Union(sub *SQL) *SQL {
+	return sql.union("union", sub)
+}
 
-	return res["avg("+sql.wrap(field)+")"], nil
+// UnionAll combine the results of the subquery by "union all".
+func (sql *SQL) UnionAll(sub *SQL) *SQL {
+	return sql.union("union all", sub)
 }
 
-// WhereRaw set WhereRaws and arguments.
-func (sql *SQL) WhereRaw(raw string, args ...interface{}) *SQL {
-	sql.WhereRaws = raw
-	sql.Args = append(sql.Args, args...)
+// Intersect combine the results of the subquery by "intersect". The terminal
+// methods return an error wrapping dialect.ErrUnsupported on mysql.
+func (sql *SQL) Intersect(sub *SQL) *SQL {
+	return sql.union("intersect", sub)
+}
+
+// Except combine the results of the subquery by "except". The terminal
+// methods return an error wrapping dialect.ErrUnsupported on mysql.
+func (sql *SQL) Except(sub *SQL) *SQL {
+	return sql.union("except", sub)
+}
+
+func (sql *SQL) union(typ string, sub *SQL) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.Unions = append(sql.Unions, dialect.Union{
+		Type: typ,
+		Sub:  &sub.SQLComponent,
+	})
 	return sql
 }
 
-// UpdateRaw set UpdateRaw.
-func (sql *SQL) UpdateRaw(raw string, args ...interface{}) *SQL {
-	sql.UpdateRaws = append(sql.UpdateRaws, dialect.RawUpdate{
-		Expression: raw,
-		Args:       args,
+// With prefix the statement with the common table expression of given name,
+// which can be used as a table then.
+func (sql *SQL) With(name string, sub *SQL) *SQL {
+	sql.errs = append(sql.errs, sub.errs...)
+	sql.Ctes = append(sql.Ctes, dialect.Cte{
+		Name: name,
+		Sub:  &sub.SQLComponent,
 	})
 	return sql
 }
 
-// LeftJoin add a left join info.
-func (sql *SQL) LeftJoin(table string, fieldA string, operation string, fieldB string) *SQL {
-	sql.Leftjoins = append(sql.Leftjoins, dialect.Join{
-		FieldA:    fieldA,
-		FieldB:    fieldB,
-		Table:     table,
-		Operation: operation,
+// WithRecursive prefix the statement with the recursive common table
+// expression of given name and columns, which combines the anchor and the
+// recursive member referring to name by "union all".
+//
+// For example:
+//
+//	Table("tree").WithRecursive("tree", []string{"id", "parent_id"},
+//		Table("categories").Select("id", "parent_id").Where("id", "=", 1),
+//		Table("categories").Select("categories.id", "categories.parent_id").
+//			Join("tree", "tree.id", "=", "categories.parent_id"))
+func (sql *SQL) WithRecursive(name string, columns []string, anchor, recursive *SQL) *SQL {
+	// the errors of recursive are collected into anchor by union
+	anchor.union("union all", recursive)
+	sql.errs = append(sql.errs, anchor.errs...)
+	sql.Ctes = append(sql.Ctes, dialect.Cte{
+		Name:      name,
+		Columns:   columns,
+		Recursive: true,
+		Sub:       &anchor.SQLComponent,
 	})
 	return sql
 }
 
+// *******************************
+// Compile method
+// *******************************
+
+// ToSQL return the select statement and arguments of SQL without executing
+// it. The SQL is not recycled, so that it can still be executed after.
+func (sql *SQL) ToSQL() (string, []interface{}, error) {
+	return sql.compile(dialect.Dialect.Select)
+}
+
+// ToInsertSQL return the insert statement of given values and arguments
+// without executing it.
+func (sql *SQL) ToInsertSQL(values dialect.H) (string, []interface{}, error) {
+	sql.Values = values
+	return sql.compile(dialect.Dialect.Insert)
+}
+
+// ToUpdateSQL return the update statement of given values and arguments
+// without executing it.
+func (sql *SQL) ToUpdateSQL(values dialect.H) (string, []interface{}, error) {
+	sql.Values = values
+	return sql.compile(dialect.Dialect.Update)
+}
+
+// ToDeleteSQL return the delete statement and arguments without executing it.
+func (sql *SQL) ToDeleteSQL() (string, []interface{}, error) {
+	return sql.compile(dialect.Dialect.Delete)
+}
+
+func (sql *SQL) compile(render func(d dialect.Dialect, comp *dialect.SQLComponent) (string, error)) (string, []interface{}, error) {
+	if err := sql.Err(); err != nil {
+		return "", nil, err
+	}
+	if sql.dialect == nil {
+		return "", nil, ErrDriverNotFound
+	}
+	statement, err := render(sql.dialect, &sql.SQLComponent)
+	if err != nil {
+		return "", nil, err
+	}
+	return statement, sql.Args, nil
+}
+
+// Interpolate return the statement with the arguments inlined as the literals
+// of the dialect of SQL, which is only for logging and debugging. Use the
+// statement and arguments of ToSQL to query.
+func (sql *SQL) Interpolate() (string, error) {
+	statement, args, err := sql.ToSQL()
+	if err != nil {
+		return "", err
+	}
+	return dialect.Interpolate(sql.dialect, statement, args...), nil
+}
+
 // *******************************
 // Transaction method
 // *******************************
@@ -343,7 +1033,14 @@ type TxFn func(tx *dbsql.Tx) (error, map[string]interface{})
 // catch the error.
 func (sql *SQL) WithTransaction(fn TxFn) (res map[string]interface{}, err error) {
 
-	tx := sql.diver.BeginTxAndConnection(sql.conn)
+	if err = sql.check(); err != nil {
+		return nil, err
+	}
+
+	tx, err := sql.diver.BeginTxAndConnectionContext(sql.ctx, sql.conn)
+	if err != nil {
+		return nil, err
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -367,7 +1064,14 @@ func (sql *SQL) WithTransaction(fn TxFn) (res map[string]interface{}, err error)
 // of given transaction level and catch the error.
 func (sql *SQL) WithTransactionByLevel(level dbsql.IsolationLevel, fn TxFn) (res map[string]interface{}, err error) {
 
-	tx := sql.diver.BeginTxWithLevelAndConnection(sql.conn, level)
+	if err = sql.check(); err != nil {
+		return nil, err
+	}
+
+	tx, err := sql.diver.BeginTxWithLevelAndConnectionContext(sql.ctx, sql.conn, level)
+	if err != nil {
+		return nil, err
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -398,17 +1102,23 @@ func (sql *SQL) WithTransactionByLevel(level dbsql.IsolationLevel, fn TxFn) (res
 func (sql *SQL) First() (map[string]interface{}, error) {
 	defer RecycleSQL(sql)
 
-	sql.dialect.Select(&sql.SQLComponent)
+	if err := sql.check(); err != nil {
+		return nil, err
+	}
 
 	var (
 		res []map[string]interface{}
 		err error
 	)
 
+	if _, err = sql.dialect.Select(&sql.SQLComponent); err != nil {
+		return nil, err
+	}
+
 	if sql.tx != nil {
-		res, err = sql.diver.QueryWithTx(sql.tx, sql.Statement, sql.Args...)
+		res, err = sql.diver.QueryWithTxContext(sql.txContext(), sql.tx, sql.Statement, sql.Args...)
 	} else {
-		res, err = sql.diver.QueryWithConnection(sql.conn, sql.Statement, sql.Args...)
+		res, err = sql.diver.QueryWithConnectionContext(sql.ctx, sql.readConnection(), sql.Statement, sql.Args...)
 	}
 
 	if err != nil {
@@ -416,7 +1126,7 @@ func (sql *SQL) First() (map[string]interface{}, error) {
 	}
 
 	if len(res) < 1 {
-		return nil, errors.New("out of index")
+		return nil, ErrNoRows
 	}
 	return res[0], nil
 }
@@ -425,26 +1135,74 @@ func (sql *SQL) First() (map[string]interface{}, error) {
 func (sql *SQL) All() ([]map[string]interface{}, error) {
 	defer RecycleSQL(sql)
 
-	sql.dialect.Select(&sql.SQLComponent)
+	if err := sql.check(); err != nil {
+		return nil, err
+	}
+
+	if _, err := sql.dialect.Select(&sql.SQLComponent); err != nil {
+		return nil, err
+	}
+
+	if sql.tx != nil {
+		return sql.diver.QueryWithTxContext(sql.txContext(), sql.tx, sql.Statement, sql.Args...)
+	}
+	return sql.diver.QueryWithConnectionContext(sql.ctx, sql.readConnection(), sql.Statement, sql.Args...)
+}
+
+// Cursor query the result and return a cursor of it, which converts one row
+// at a time. The cursor must be closed after use.
+func (sql *SQL) Cursor() (*Rows, error) {
+	defer RecycleSQL(sql)
+
+	if err := sql.check(); err != nil {
+		return nil, err
+	}
+
+	if _, err := sql.dialect.Select(&sql.SQLComponent); err != nil {
+		return nil, err
+	}
 
 	if sql.tx != nil {
-		return sql.diver.QueryWithTx(sql.tx, sql.Statement, sql.Args...)
+		return sql.diver.QueryRowsWithTxContext(sql.txContext(), sql.tx, sql.Statement, sql.Args...)
 	}
-	return sql.diver.QueryWithConnection(sql.conn, sql.Statement, sql.Args...)
+	return sql.diver.QueryRowsWithConnectionContext(sql.ctx, sql.readConnection(), sql.Statement, sql.Args...)
+}
+
+// FirstInto query the result and scan the first row into dst, which is a
+// pointer to a struct whose fields are mapped to the columns by the `db` tags.
+// ErrNoRows is returned if there is no result.
+func (sql *SQL) FirstInto(dst interface{}) error {
+	rows, err := sql.Cursor()
+	return scanFirst(dst, rows, err)
+}
+
+// AllInto query all the result and scan them into dst, which is a pointer to
+// a slice of struct or pointer to struct.
+func (sql *SQL) AllInto(dst interface{}) error {
+	rows, err := sql.Cursor()
+	return scanAll(dst, rows, err)
 }
 
 // ShowColumns show columns info.
 func (sql *SQL) ShowColumns() ([]map[string]interface{}, error) {
 	defer RecycleSQL(sql)
 
-	return sql.diver.QueryWithConnection(sql.conn, sql.dialect.ShowColumns(sql.TableName))
+	if err := sql.check(); err != nil {
+		return nil, err
+	}
+
+	return sql.diver.QueryWithConnectionContext(sql.ctx, sql.conn, sql.dialect.ShowColumns(sql.TableName))
 }
 
 // ShowTables show table info.
 func (sql *SQL) ShowTables() ([]map[string]interface{}, error) {
 	defer RecycleSQL(sql)
 
-	return sql.diver.QueryWithConnection(sql.conn, sql.dialect.ShowTables())
+	if err := sql.check(); err != nil {
+		return nil, err
+	}
+
+	return sql.diver.QueryWithConnectionContext(sql.ctx, sql.conn, sql.dialect.ShowTables())
 }
 
 // Update exec the update method of given key/value pairs.
@@ -453,25 +1211,22 @@ func (sql *SQL) Update(values dialect.H) (int64, error) {
 
 	sql.Values = values
 
-	sql.dialect.Update(&sql.SQLComponent)
-
-	var (
-		res dbsql.Result
-		err error
-	)
+	if err := sql.check(); err != nil {
+		return 0, err
+	}
 
-	if sql.tx != nil {
-		res, err = sql.diver.ExecWithTx(sql.tx, sql.Statement, sql.Args...)
-	} else {
-		res, err = sql.diver.ExecWithConnection(sql.conn, sql.Statement, sql.Args...)
+	if _, err := sql.dialect.Update(&sql.SQLComponent); err != nil {
+		return 0, err
 	}
 
+	res, err := sql.exec()
+
 	if err != nil {
 		return 0, err
 	}
 
 	if affectRow, _ := res.RowsAffected(); affectRow < 1 {
-		return 0, errors.New("no affect row")
+		return 0, ErrNoAffectRow
 	}
 
 	return res.LastInsertId()
@@ -481,25 +1236,22 @@ func (sql *SQL) Update(values dialect.H) (int64, error) {
 func (sql *SQL) Delete() error {
 	defer RecycleSQL(sql)
 
-	sql.dialect.Delete(&sql.SQLComponent)
-
-	var (
-		res dbsql.Result
-		err error
-	)
+	if err := sql.check(); err != nil {
+		return err
+	}
 
-	if sql.tx != nil {
-		res, err = sql.diver.ExecWithTx(sql.tx, sql.Statement, sql.Args...)
-	} else {
-		res, err = sql.diver.ExecWithConnection(sql.conn, sql.Statement, sql.Args...)
+	if _, err := sql.dialect.Delete(&sql.SQLComponent); err != nil {
+		return err
 	}
 
+	res, err := sql.exec()
+
 	if err != nil {
 		return err
 	}
 
 	if affectRow, _ := res.RowsAffected(); affectRow < 1 {
-		return errors.New("no affect row")
+		return ErrNoAffectRow
 	}
 
 	return nil
@@ -509,25 +1261,22 @@ func (sql *SQL) Delete() error {
 func (sql *SQL) Exec() (int64, error) {
 	defer RecycleSQL(sql)
 
-	sql.dialect.Update(&sql.SQLComponent)
-
-	var (
-		res dbsql.Result
-		err error
-	)
+	if err := sql.check(); err != nil {
+		return 0, err
+	}
 
-	if sql.tx != nil {
-		res, err = sql.diver.ExecWithTx(sql.tx, sql.Statement, sql.Args...)
-	} else {
-		res, err = sql.diver.ExecWithConnection(sql.conn, sql.Statement, sql.Args...)
+	if _, err := sql.dialect.Update(&sql.SQLComponent); err != nil {
+		return 0, err
 	}
 
+	res, err := sql.exec()
+
 	if err != nil {
 		return 0, err
 	}
 
 	if affectRow, _ := res.RowsAffected(); affectRow < 1 {
-		return 0, errors.New("no affect row")
+		return 0, ErrNoAffectRow
 	}
 
 	return res.LastInsertId()
@@ -535,55 +1284,284 @@ func (sql *SQL) Exec() (int64, error) {
 
 // Insert exec the insert method of given key/value pairs.
 func (sql *SQL) Insert(values dialect.H) (int64, error) {
+	return sql.insert(values, "")
+}
+
+// insert exec the insert method of given values, and return the value of the
+// returning column instead of the last insert id if the dialect supports it.
+func (sql *SQL) insert(values dialect.H, returning string) (int64, error) {
 	defer RecycleSQL(sql)
 
 	sql.Values = values
+	sql.Returning = returning
+
+	if err := sql.check(); err != nil {
+		return 0, err
+	}
+
+	if _, err := sql.dialect.Insert(&sql.SQLComponent); err != nil {
+		return 0, err
+	}
+
+	if sql.Returning != "" {
+		return sql.insertReturning()
+	}
+
+	res, err := sql.exec()
 
-	sql.dialect.Insert(&sql.SQLComponent)
+	if err != nil {
+		return 0, err
+	}
+
+	if affectRow, _ := res.RowsAffected(); affectRow < 1 {
+		return 0, ErrNoAffectRow
+	}
+
+	return res.LastInsertId()
+}
 
+// insertReturning queries the rendered insert statement, which returns the
+// value of the returning column in the only row.
+func (sql *SQL) insertReturning() (int64, error) {
 	var (
-		res dbsql.Result
+		res []map[string]interface{}
 		err error
 	)
 
 	if sql.tx != nil {
-		res, err = sql.diver.ExecWithTx(sql.tx, sql.Statement, sql.Args...)
+		res, err = sql.diver.QueryWithTxContext(sql.txContext(), sql.tx, sql.Statement, sql.Args...)
 	} else {
-		res, err = sql.diver.ExecWithConnection(sql.conn, sql.Statement, sql.Args...)
+		res, err = sql.diver.QueryWithConnectionContext(sql.ctx, sql.conn, sql.Statement, sql.Args...)
 	}
 
 	if err != nil {
 		return 0, err
 	}
 
-	if affectRow, _ := res.RowsAffected(); affectRow < 1 {
-		return 0, errors.New("no affect row")
+	sql.written()
+
+	if len(res) < 1 {
+		return 0, ErrNoAffectRow
 	}
 
-	return res.LastInsertId()
+	switch id := res[0][sql.Returning].(type) {
+	case int64:
+		return id, nil
+	case []uint8:
+		return strconv.ParseInt(string(id), 10, 64)
+	case string:
+		return strconv.ParseInt(id, 10, 64)
+	default:
+		return 0, fmt.Errorf("connection: unexpected returning %s %T", sql.Returning, id)
+	}
+}
+
+// InsertBatch exec the insert method of given rows in multi-row statements and
+// return the total affected rows. The rows must have the same columns. They are
+// split into chunks to respect the placeholder and row limits of the driver,
+// and the chunks are not atomic unless the SQL is within a transaction.
+func (sql *SQL) InsertBatch(rows []dialect.H) (int64, error) {
+	defer RecycleSQL(sql)
+
+	if err := sql.check(); err != nil {
+		return 0, err
+	}
+
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return 0, dialect.ErrEmptyInsert
+	}
+
+	size := sql.dialect.GetMaxPlaceholders() / len(rows[0])
+	if size < 1 {
+		return 0, dialect.ErrTooManyColumns
+	}
+	if maxRows := sql.dialect.GetMaxRows(); maxRows > 0 && size > maxRows {
+		size = maxRows
+	}
+
+	var total int64
+
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		sql.Batch = rows[start:end]
+
+		if _, err := sql.dialect.InsertBatch(&sql.SQLComponent); err != nil {
+			return total, err
+		}
+
+		res, err := sql.exec()
+
+		if err != nil {
+			return total, err
+		}
+
+		affectRow, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affectRow
+	}
+
+	return total, nil
+}
+
+// Upsert exec the insert method of given key/value pairs, and update the row
+// instead when it conflicts with an existing one on the conflict columns. If
+// updateColumns is empty, all the columns except the conflict ones are updated.
+// The conflict columns are ignored by mysql, which uses the unique keys of the
+// table. It returns the affected rows reported by the driver.
+func (sql *SQL) Upsert(values dialect.H, conflictColumns []string, updateColumns []string) (int64, error) {
+	defer RecycleSQL(sql)
+
+	sql.Values = values
+	sql.ConflictColumns = conflictColumns
+	sql.UpdateColumns = updateColumns
+
+	if err := sql.check(); err != nil {
+		return 0, err
+	}
+
+	if _, err := sql.dialect.Upsert(&sql.SQLComponent); err != nil {
+		return 0, err
+	}
+
+	res, err := sql.exec()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// InsertStruct exec the insert method of the columns of given struct, which are
+// mapped by the `db` tags. The fields tagged with "readonly" are skipped, and so
+// are the zero fields tagged with "omitempty" or "pk". If v is a pointer and the
+// primary key of it is a zero integer, the generated id is written back to it.
+// The other primary keys, e.g. the UUID strings, are inserted as they are.
+func (sql *SQL) InsertStruct(v interface{}) (int64, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		RecycleSQL(sql)
+		return 0, err
+	}
+
+	values, pks := structValues(rv)
+
+	// the zero integer primary key is generated by the database, which is
+	// returned by the insert statement where LastInsertId is not supported
+	returning := ""
+	for _, pk := range pks {
+		if !isIntKind(rv.Type().FieldByIndex(pk.index).Type.Kind()) {
+			continue
+		}
+		if fv, ok := fieldValue(rv, pk.index); !ok || fv.IsZero() {
+			returning = pk.column
+			break
+		}
+	}
+
+	id, err := sql.insert(values, returning)
+	if err != nil {
+		return id, err
+	}
+
+	// rv is addressable only if v is a pointer
+	if returning != "" && rv.CanAddr() {
+		for _, pk := range pks {
+			if pk.column != returning {
+				continue
+			}
+			fv := fieldByIndex(rv, pk.index)
+			switch fv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				fv.SetInt(id)
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				fv.SetUint(uint64(id))
+			}
+		}
+	}
+
+	return id, nil
+}
+
+// isIntKind reports whether k is a kind of integer.
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
-func (sql *SQL) wrap(field string) string {
-	return sql.diver.GetDelimiter() + field + sql.diver.GetDelimiter()
+// UpdateStruct exec the update method of the columns of given struct, which are
+// mapped by the `db` tags. The fields tagged with "pk" are used as the where
+// conditions instead of being updated, and ErrZeroPrimaryKey is returned if
+// any of them is zero. ErrNoPrimaryKey is returned if there is no field tagged
+// with "pk" and no where condition, instead of updating all the rows. The
+// fields tagged with "readonly" are skipped, and so are the zero fields tagged
+// with "omitempty".
+func (sql *SQL) UpdateStruct(v interface{}) (int64, error) {
+	rv, err := structValue(v)
+	if err != nil {
+		RecycleSQL(sql)
+		return 0, err
+	}
+
+	values, pks := structValues(rv)
+
+	if len(pks) == 0 && len(sql.Wheres) == 0 {
+		RecycleSQL(sql)
+		return 0, ErrNoPrimaryKey
+	}
+
+	for _, pk := range pks {
+		fv, ok := fieldValue(rv, pk.index)
+		if !ok || fv.IsZero() {
+			RecycleSQL(sql)
+			return 0, ErrZeroPrimaryKey
+		}
+		delete(values, pk.column)
+		sql.Where(pk.column, "=", fv.Interface())
+	}
+
+	return sql.Update(values)
 }
 
 // RecycleSQL clear the SQL and put into the pool.
 func RecycleSQL(sql *SQL) {
 
-	fmt.Println(sql.Statement, sql.Args)
-
-	sql.Fields = make([]string, 0)
+	sql.Fields = make([]dialect.Field, 0)
+	sql.From = nil
+	sql.Unions = nil
+	sql.Ctes = nil
 	sql.TableName = ""
 	sql.Wheres = make([]dialect.Where, 0)
-	sql.Leftjoins = make([]dialect.Join, 0)
+	sql.Havings = nil
+	sql.Joins = make([]dialect.Join, 0)
 	sql.Args = make([]interface{}, 0)
-	sql.Order = ""
+	sql.Orders = nil
+	sql.Groups = nil
 	sql.Offset = ""
 	sql.Limit = ""
-	sql.WhereRaws = ""
 	sql.UpdateRaws = make([]dialect.RawUpdate, 0)
 	sql.Statement = ""
+	sql.Values = nil
+	sql.Batch = nil
+	sql.ConflictColumns = nil
+	sql.UpdateColumns = nil
+	sql.Returning = ""
+	sql.diver = nil
+	sql.dialect = nil
 	sql.tx = nil
+	sql.errs = nil
+	sql.primary = false
+	sql.ctx = context.Background()
 
 	SQLPool.Put(sql)
 }
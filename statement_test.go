@@ -0,0 +1,648 @@
+package connection
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chenhg5/go-sql/dialect"
+)
+
+// recordConn is the Connection of the driver which records the statements
+// instead of executing them.
+type recordConn struct {
+	Connection
+	driver     string
+	statements []string
+}
+
+func (c *recordConn) Name() string {
+	return c.driver
+}
+
+func (c *recordConn) QueryWithConnectionContext(ctx context.Context, con, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	c.statements = append(c.statements, query)
+	return []map[string]interface{}{{"id": int64(1)}}, nil
+}
+
+func (c *recordConn) ExecWithConnectionContext(ctx context.Context, con, query string, args ...interface{}) (sql.Result, error) {
+	c.statements = append(c.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+type StructBase struct {
+	ID int64 `db:"id,pk"`
+}
+
+type embeddedUser struct {
+	*StructBase
+	Name string `db:"name"`
+}
+
+type uuidUser struct {
+	UUID string `db:"uuid,pk"`
+	Name string `db:"name"`
+}
+
+type plainUser struct {
+	Name string `db:"name"`
+	Age  int    `db:"age"`
+}
+
+func TestInsertStruct(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn,
+		"create table users (id integer primary key autoincrement, name text)",
+		"create table uuid_users (uuid text primary key, name text)",
+	)
+
+	t.Run("by value", func(t *testing.T) {
+		id, err := WithDriver(conn).Table("users").InsertStruct(embeddedUser{Name: "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 1 {
+			t.Errorf("id = %d, want 1", id)
+		}
+	})
+
+	t.Run("nil embedded pointer", func(t *testing.T) {
+		user := &embeddedUser{Name: "b"}
+		id, err := WithDriver(conn).Table("users").InsertStruct(user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 2 || user.StructBase == nil || user.ID != 2 {
+			t.Errorf("id = %d, user = %+v, want the id written back", id, user.StructBase)
+		}
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		base := &StructBase{}
+		user := &embeddedUser{StructBase: base, Name: "c"}
+		if _, err := WithDriver(conn).Table("users").InsertStruct(user); err != nil {
+			t.Fatal(err)
+		}
+		if user.StructBase != base || base.ID != 3 {
+			t.Errorf("base = %+v, want the id written back to it", base)
+		}
+	})
+
+	t.Run("string primary key", func(t *testing.T) {
+		user := &uuidUser{UUID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Name: "d"}
+		if _, err := WithDriver(conn).Table("uuid_users").InsertStruct(user); err != nil {
+			t.Fatal(err)
+		}
+		if user.UUID != "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
+			t.Errorf("uuid = %q", user.UUID)
+		}
+	})
+}
+
+func TestInsertStructReturning(t *testing.T) {
+	tests := []struct {
+		name      string
+		driver    string
+		v         interface{}
+		statement string
+	}{
+		{
+			name:      "integer",
+			driver:    "postgresql",
+			v:         embeddedUser{Name: "a"},
+			statement: `insert into "users" ("name") values ($1) returning "id"`,
+		},
+		{
+			name:      "string",
+			driver:    "postgresql",
+			v:         uuidUser{Name: "a"},
+			statement: `insert into "users" ("name") values ($1)`,
+		},
+		{
+			name:      "mssql integer",
+			driver:    "mssql",
+			v:         embeddedUser{Name: "a"},
+			statement: `insert into [users] ([name]) output inserted.[id] values (@p1)`,
+		},
+		{
+			name:      "mssql string",
+			driver:    "mssql",
+			v:         uuidUser{Name: "a"},
+			statement: `insert into [users] ([name]) values (@p1)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &recordConn{driver: tt.driver}
+			_, _ = WithDriver(conn).Table("users").InsertStruct(tt.v)
+			if len(conn.statements) != 1 || conn.statements[0] != tt.statement {
+				t.Errorf("statements = %q, want %q", conn.statements, tt.statement)
+			}
+		})
+	}
+}
+
+func TestUpdateStruct(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn,
+		"create table users (id integer primary key, name text, age integer)",
+		"insert into users values (1, 'a', 10), (2, 'b', 20), (3, 'c', 30)",
+	)
+
+	if _, err := WithDriver(conn).Table("users").UpdateStruct(plainUser{Name: "x"}); err != ErrNoPrimaryKey {
+		t.Errorf("err = %v, want ErrNoPrimaryKey", err)
+	}
+	if _, err := WithDriver(conn).Table("users").UpdateStruct(embeddedUser{Name: "x"}); err != ErrZeroPrimaryKey {
+		t.Errorf("err = %v, want ErrZeroPrimaryKey", err)
+	}
+
+	if _, err := WithDriver(conn).Table("users").Where("id", "=", 2).UpdateStruct(plainUser{Name: "x", Age: 21}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := conn.Query("select name from users order by id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0]["name"] != "a" || res[1]["name"] != "x" || res[2]["name"] != "c" {
+		t.Errorf("rows = %v", res)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	tests := []struct {
+		fields    []string
+		statement string
+	}{
+		{[]string{"id", "name"}, "select `id`,`name` from `orders`"},
+		{[]string{"o.id", "o.*"}, "select `o`.`id`,`o`.* from `orders`"},
+		{[]string{"name as n", "name n"}, "select `name` as `n`,`name` as `n` from `orders`"},
+		{[]string{"count(*)"}, "select count(*) from `orders`"},
+		{[]string{"max(id) as m"}, "select max(`id`) as `m` from `orders`"},
+		{[]string{"MAX(id) AS m"}, "select MAX(`id`) as `m` from `orders`"},
+		{[]string{"count(distinct user_id) as users"}, "select count(distinct `user_id`) as `users` from `orders`"},
+		{[]string{"sum(price) * 2"}, "select sum(price) * 2 from `orders`"},
+		{[]string{"sum(price) * 2 as double_total"}, "select sum(price) * 2 as `double_total` from `orders`"},
+		{[]string{"price * quantity"}, "select price * quantity from `orders`"},
+		{[]string{"coalesce(sum(price), 0) as total"}, "select coalesce(sum(price), 0) as `total` from `orders`"},
+		{[]string{"cast(price as char)"}, "select cast(price as char) from `orders`"},
+	}
+	for _, tt := range tests {
+		statement, _, err := Table("orders").WithDialect(dialect.GetDialectByDriver("mysql")).Select(tt.fields...).ToSQL()
+		if err != nil {
+			t.Fatalf("%v: %v", tt.fields, err)
+		}
+		if statement != tt.statement {
+			t.Errorf("Select(%q):\ngot:  %s\nwant: %s", tt.fields, statement, tt.statement)
+		}
+	}
+}
+
+// renderTest is the case of a builder rendered by the dialect of driver.
+type renderTest struct {
+	name      string
+	driver    string
+	sql       func(sql *SQL) *SQL
+	statement string
+	args      []interface{}
+}
+
+func testRender(t *testing.T, tests []renderTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := Table("users").WithDialect(dialect.GetDialectByDriver(tt.driver))
+			statement, args, err := tt.sql(sql).ToSQL()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statement != tt.statement {
+				t.Errorf("statement:\ngot:  %s\nwant: %s", statement, tt.statement)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("args = %v, want %v", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestWhereGroup(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "or where",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("a", "=", 1).OrWhere("b", "=", 2)
+			},
+			statement: `select * from "users" where "a" = $1 or "b" = $2`,
+			args:      []interface{}{1, 2},
+		},
+		{
+			name:   "group",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereGroup(func(group *SQL) {
+					group.Where("a", "=", 1).OrWhere("b", "=", 2)
+				}).Where("c", "=", 3)
+			},
+			statement: `select * from "users" where ("a" = $1 or "b" = $2) and "c" = $3`,
+			args:      []interface{}{1, 2, 3},
+		},
+		{
+			name:   "nested or group",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("a", "=", 1).OrWhereGroup(func(group *SQL) {
+					group.Where("b", "=", 2).WhereGroup(func(group *SQL) {
+						group.Where("c", "=", 3).OrWhereRaw("d > ?", 4)
+					})
+				})
+			},
+			statement: `select * from "users" where "a" = $1 or ("b" = $2 and ("c" = $3 or (d > $4)))`,
+			args:      []interface{}{1, 2, 3, 4},
+		},
+		{
+			name:   "empty group",
+			driver: "mysql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereGroup(func(group *SQL) {}).Where("a", "=", 1)
+			},
+			statement: "select * from `users` where `a` = ?",
+			args:      []interface{}{1},
+		},
+	})
+}
+
+func TestWhereHelpers(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "null",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereNull("a").WhereNotNull("b").Where("c", "=", 1)
+			},
+			statement: `select * from "users" where "a" is null and "b" is not null and "c" = $1`,
+			args:      []interface{}{1},
+		},
+		{
+			name:   "between",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("a", "=", 1).WhereBetween("b", 2, 3).WhereNotBetween("c", 4, 5)
+			},
+			statement: `select * from "users" where "a" = $1 and "b" between $2 and $3 and "c" not between $4 and $5`,
+			args:      []interface{}{1, 2, 3, 4, 5},
+		},
+		{
+			name:   "in",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereIn("a", []int{1, 2}).WhereNotIn("b", []string{"x"})
+			},
+			statement: `select * from "users" where "a" in ($1,$2) and "b" not in ($3)`,
+			args:      []interface{}{1, 2, "x"},
+		},
+		{
+			name:   "like",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereLike("name", "a%").Where("id", ">", 1)
+			},
+			statement: `select * from "users" where "name" like $1 and "id" > $2`,
+			args:      []interface{}{"a%", 1},
+		},
+		{
+			name:   "like escape",
+			driver: "sqlite",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereLike("name", "%"+dialect.EscapeLike("50%")+"%")
+			},
+			statement: "select * from `users` where `name` like ? escape '\\'",
+			args:      []interface{}{`%50\%%`},
+		},
+		{
+			name:   "column",
+			driver: "mysql",
+			sql: func(sql *SQL) *SQL {
+				return sql.WhereColumn("users.updated_at", ">", "users.created_at")
+			},
+			statement: "select * from `users` where `users`.`updated_at` > `users`.`created_at`",
+			args:      []interface{}{},
+		},
+		{
+			name:   "exists",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("a", "=", 1).WhereNotExists(Table("orders").
+					WhereColumn("orders.user_id", "=", "users.id").Where("orders.state", "=", 2))
+			},
+			statement: `select * from "users" where "a" = $1 and not exists (select * from "orders" where "orders"."user_id" = "users"."id" and "orders"."state" = $2)`,
+			args:      []interface{}{1, 2},
+		},
+	})
+}
+
+func TestJoins(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "inner and left",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Join("roles", "users.role_id", "=", "roles.id").
+					LeftJoin("teams", "users.team_id", "=", "teams.id").Where("users.id", "=", 1)
+			},
+			statement: `select * from "users" inner join "roles" on "users"."role_id" = "roles"."id"  left join "teams" on "users"."team_id" = "teams"."id"  where "users"."id" = $1`,
+			args:      []interface{}{1},
+		},
+		{
+			name:   "right full and cross",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.RightJoin("roles", "users.role_id", "=", "roles.id").
+					FullJoin("teams", "users.team_id", "=", "teams.id").CrossJoin("days")
+			},
+			statement: `select * from "users" right join "roles" on "users"."role_id" = "roles"."id"  full join "teams" on "users"."team_id" = "teams"."id"  cross join "days"`,
+			args:      []interface{}{},
+		},
+		{
+			name:   "join on",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("users.id").Where("users.age", ">", 1).JoinOn("roles", func(join *JoinClause) {
+					join.On("users.role_id", "=", "roles.id").OrOn("users.alt_role_id", "=", "roles.id").
+						Where("roles.enabled", "=", 2).OrWhere("roles.level", ">", 3)
+				}).LeftJoinOn("teams", func(join *JoinClause) {
+					join.On("users.team_id", "=", "teams.id").Where("teams.state", "=", 4)
+				})
+			},
+			statement: `select "users"."id" from "users" inner join "roles" on "users"."role_id" = "roles"."id" or "users"."alt_role_id" = "roles"."id" and "roles"."enabled" = $1 or "roles"."level" > $2  left join "teams" on "users"."team_id" = "teams"."id" and "teams"."state" = $3  where "users"."age" > $4`,
+			args:      []interface{}{2, 3, 4, 1},
+		},
+	})
+
+	_, _, err := Table("users").WithDialect(dialect.GetDialectByDriver("mysql")).
+		FullJoin("teams", "users.team_id", "=", "teams.id").ToSQL()
+	if !errors.Is(err, dialect.ErrUnsupported) {
+		t.Errorf("err of mysql full join = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestSubqueries(t *testing.T) {
+	orders := func(state int) *SQL {
+		return Table("orders").Select("user_id").Where("state", "=", state)
+	}
+	testRender(t, []renderTest{
+		{
+			name:   "select sub",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("id").SelectSub(Table("orders").SelectRaw("count(*)").
+					WhereColumn("orders.user_id", "=", "users.id").Where("state", "=", 1), "orders").
+					Where("age", ">", 2)
+			},
+			statement: `select "id",(select count(*) from "orders" where "orders"."user_id" = "users"."id" and "state" = $1) as "orders" from "users" where "age" > $2`,
+			args:      []interface{}{1, 2},
+		},
+		{
+			name:   "from sub",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.FromSub(orders(1), "o").Where("user_id", ">", 2)
+			},
+			statement: `select * from (select "user_id" from "orders" where "state" = $1) as "o" where "user_id" > $2`,
+			args:      []interface{}{1, 2},
+		},
+		{
+			name:   "where in sub",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("age", ">", 1).WhereIn("id", orders(2)).WhereNotIn("id", orders(3))
+			},
+			statement: `select * from "users" where "age" > $1 and "id" in (select "user_id" from "orders" where "state" = $2) and "id" not in (select "user_id" from "orders" where "state" = $3)`,
+			args:      []interface{}{1, 2, 3},
+		},
+		{
+			name:   "all positions",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.SelectSub(orders(1).Take(1), "last").
+					FromSub(Table("users").Where("age", ">", 2), "u").
+					JoinSub(orders(3), "o", "o.user_id", "=", "u.id").
+					WhereExists(orders(4).WhereColumn("orders.user_id", "=", "u.id")).
+					Where("u.name", "=", "a")
+			},
+			statement: `select (select "user_id" from "orders" where "state" = $1 limit 1 ) as "last" from (select * from "users" where "age" > $2) as "u" inner join (select "user_id" from "orders" where "state" = $3) as "o" on "o"."user_id" = "u"."id"  where exists (select "user_id" from "orders" where "state" = $4 and "orders"."user_id" = "u"."id") and "u"."name" = $5`,
+			args:      []interface{}{1, 2, 3, 4, "a"},
+		},
+		{
+			name:   "mssql",
+			driver: "mssql",
+			sql: func(sql *SQL) *SQL {
+				return sql.FromSub(orders(1), "o").WhereIn("user_id", orders(2)).Where("user_id", ">", 3)
+			},
+			statement: `select * from (select [user_id] from [orders] where [state] = @p1) as [o] where [user_id] in (select [user_id] from [orders] where [state] = @p2) and [user_id] > @p3`,
+			args:      []interface{}{1, 2, 3},
+		},
+	})
+}
+
+func TestUnions(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "union",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("id").Where("age", ">", 1).
+					Union(Table("admins").Select("id").Where("level", "=", 2)).
+					UnionAll(Table("guests").Select("id").Where("state", "=", 3))
+			},
+			statement: `select "id" from "users" where "age" > $1 union select "id" from "admins" where "level" = $2 union all select "id" from "guests" where "state" = $3`,
+			args:      []interface{}{1, 2, 3},
+		},
+		{
+			name:   "intersect and except",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("id").Where("age", ">", 1).
+					Intersect(Table("admins").Select("id").Where("level", "=", 2)).
+					Except(Table("guests").Select("id").Where("state", "=", 3))
+			},
+			statement: `select "id" from "users" where "age" > $1 intersect select "id" from "admins" where "level" = $2 except select "id" from "guests" where "state" = $3`,
+			args:      []interface{}{1, 2, 3},
+		},
+		{
+			name:   "order of compound",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("id").Where("age", ">", 1).
+					Union(Table("admins").Select("id").Where("level", "=", 2)).
+					OrderBy("id").Take(10)
+			},
+			statement: `select "id" from "users" where "age" > $1 union select "id" from "admins" where "level" = $2 order by "id"  limit 10 `,
+			args:      []interface{}{1, 2},
+		},
+	})
+
+	_, _, err := Table("users").WithDialect(dialect.GetDialectByDriver("mysql")).
+		Intersect(Table("admins")).ToSQL()
+	if !errors.Is(err, dialect.ErrUnsupported) {
+		t.Errorf("err of mysql intersect = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestCtes(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "with",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return Table("active").WithDialect(sql.dialect).
+					With("active", Table("users").Where("state", "=", 1)).
+					With("paid", Table("orders").Where("paid", "=", 2)).
+					WhereIn("id", Table("paid").Select("user_id")).Where("age", ">", 3)
+			},
+			statement: `with "active" as (select * from "users" where "state" = $1), "paid" as (select * from "orders" where "paid" = $2) select * from "active" where "id" in (select "user_id" from "paid") and "age" > $3`,
+			args:      []interface{}{1, 2, 3},
+		},
+		{
+			name:   "with recursive",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return Table("tree").WithDialect(sql.dialect).
+					WithRecursive("tree", []string{"id", "parent_id"},
+						Table("categories").Select("id", "parent_id").Where("id", "=", 1),
+						Table("categories").Select("categories.id", "categories.parent_id").
+							Join("tree", "tree.id", "=", "categories.parent_id").Where("categories.state", "=", 2)).
+					Where("id", "<>", 3)
+			},
+			statement: `with recursive "tree" ("id","parent_id") as (select "id","parent_id" from "categories" where "id" = $1 union all select "categories"."id","categories"."parent_id" from "categories" inner join "tree" on "tree"."id" = "categories"."parent_id"  where "categories"."state" = $2) select * from "tree" where "id" <> $3`,
+			args:      []interface{}{1, 2, 3},
+		},
+	})
+}
+
+func TestOrderAndGroup(t *testing.T) {
+	testRender(t, []renderTest{
+		{
+			name:   "order by columns",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Where("age", ">", 1).OrderBy("score", "desc", "id").OrderBy("name asc nulls last")
+			},
+			statement: `select * from "users" where "age" > $1 order by "score" desc, "id", "name" asc nulls last `,
+			args:      []interface{}{1},
+		},
+		{
+			name:   "order by expression",
+			driver: "mysql",
+			sql: func(sql *SQL) *SQL {
+				return sql.OrderBy("field(id, 3, 1)").OrderByDesc("users.created_at").OrderByRaw("name is null")
+			},
+			statement: "select * from `users` order by field(id, 3, 1), `users`.`created_at` desc, name is null ",
+			args:      []interface{}{},
+		},
+		{
+			name:   "group by and having",
+			driver: "postgresql",
+			sql: func(sql *SQL) *SQL {
+				return sql.Select("role_id", "team_id", "count(id) as total").Where("age", ">", 1).
+					GroupBy("role_id", "team_id").Having("count(id)", ">", 2).OrHaving("max(age)", "<", 3).
+					OrderBy("total desc")
+			},
+			statement: `select "role_id","team_id",count("id") as "total" from "users" where "age" > $1 group by "role_id", "team_id"  having count(id) > $2 or max(age) < $3  order by "total" desc `,
+			args:      []interface{}{1, 2, 3},
+		},
+	})
+
+	tests := []func(sql *SQL) *SQL{
+		func(sql *SQL) *SQL { return sql.OrderBy() },
+		func(sql *SQL) *SQL { return sql.OrderBy("desc") },
+		func(sql *SQL) *SQL { return sql.OrderBy("id nulls") },
+		func(sql *SQL) *SQL { return sql.OrderByAsc() },
+	}
+	for i, fn := range tests {
+		if _, _, err := fn(Table("users")).ToSQL(); !errors.Is(err, ErrEmptyOrder) {
+			t.Errorf("%d: err = %v, want ErrEmptyOrder", i, err)
+		}
+	}
+	if _, _, err := Table("users").GroupBy().ToSQL(); err != ErrEmptyGroup {
+		t.Errorf("err = %v, want ErrEmptyGroup", err)
+	}
+}
+
+func TestInsertBatch(t *testing.T) {
+	conn := openSqlite(t, Database{})
+	mustExec(t, conn, "create table users (id integer primary key, name text, age integer)")
+
+	// 999 placeholders of sqlite are 333 rows of 3 columns
+	rows := make([]dialect.H, 1000)
+	for i := range rows {
+		rows[i] = dialect.H{"id": i + 1, "name": "a", "age": i}
+	}
+	affected, err := WithDriver(conn).Table("users").InsertBatch(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 1000 {
+		t.Errorf("affected = %d, want 1000", affected)
+	}
+	res, err := conn.Query("select count(*) as n, sum(age) as total from users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0]["n"] != int64(1000) || res[0]["total"] != int64(999*1000/2) {
+		t.Errorf("rows = %v, want 1000 rows inserted", res)
+	}
+
+	tests := []struct {
+		name    string
+		driver  string
+		columns int
+		rows    int
+		chunks  []int
+	}{
+		{name: "placeholders", driver: "sqlite", columns: 3, rows: 1000, chunks: []int{333, 333, 333, 1}},
+		{name: "rows", driver: "mssql", columns: 1, rows: 2500, chunks: []int{1000, 1000, 500}},
+		{name: "placeholders before rows", driver: "mssql", columns: 3, rows: 1500, chunks: []int{700, 700, 100}},
+		{name: "one chunk", driver: "postgresql", columns: 2, rows: 10, chunks: []int{10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := make([]dialect.H, tt.rows)
+			for i := range rows {
+				rows[i] = dialect.H{}
+				for j := 0; j < tt.columns; j++ {
+					rows[i]["c"+strconv.Itoa(j)] = i
+				}
+			}
+			conn := &recordConn{driver: tt.driver}
+			if _, err := WithDriver(conn).Table("users").InsertBatch(rows); err != nil {
+				t.Fatal(err)
+			}
+			chunks := make([]int, len(conn.statements))
+			for i, statement := range conn.statements {
+				chunks[i] = strings.Count(statement, "),(") + 1
+			}
+			if !reflect.DeepEqual(chunks, tt.chunks) {
+				t.Errorf("chunks = %v, want %v", chunks, tt.chunks)
+			}
+		})
+	}
+
+	for _, rows := range [][]dialect.H{
+		{{"id": 1, "name": "a"}, {"id": 2}},
+		{{"id": 1, "name": "a"}, {"id": 2, "age": 1}},
+	} {
+		if _, err := WithDriver(conn).Table("users").InsertBatch(rows); err != dialect.ErrBatchColumns {
+			t.Errorf("err = %v, want ErrBatchColumns", err)
+		}
+	}
+	if _, err := WithDriver(conn).Table("users").InsertBatch(nil); err != dialect.ErrEmptyInsert {
+		t.Errorf("err = %v, want ErrEmptyInsert", err)
+	}
+}
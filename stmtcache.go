@@ -0,0 +1,206 @@
+package connection
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+)
+
+// StmtCache is a size-bounded LRU cache of the prepared statements of a
+// connection keyed by the statement text. The least recently used statement is
+// evicted and closed once it is not in use.
+//
+// It is enabled by Database.StmtCacheSize, and used by the query and exec
+// methods of the connection. The cached statements are rebound to the
+// transactions by sql.Tx.StmtContext when the connection of them is known,
+// see TxContext, but the statements are not prepared for the transactions.
+type StmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// StmtCacheStats is the statistics of StmtCache.
+type StmtCacheStats struct {
+	// Hits is the number of statements found in the cache.
+	Hits uint64
+	// Misses is the number of statements prepared for the cache, which are
+	// not found in it.
+	Misses uint64
+	// Evictions is the number of statements evicted from the cache.
+	Evictions uint64
+	// Len is the number of statements in the cache.
+	Len int
+}
+
+// cachedStmt is an entry of StmtCache.
+type cachedStmt struct {
+	query string
+	stmt  *sql.Stmt
+	// refs is the number of callers using stmt, which is closed after the
+	// eviction until it drops to zero.
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, size int) *StmtCache {
+	return &StmtCache{
+		db:    db,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Stats return the statistics of the cache.
+func (c *StmtCache) Stats() StmtCacheStats {
+	c.mu.Lock()
+	length := c.ll.Len()
+	c.mu.Unlock()
+	return StmtCacheStats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Len:       length,
+	}
+}
+
+// Clear evicts all the statements of the cache.
+func (c *StmtCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.ll.Len() > 0 {
+		c.evict(c.ll.Back())
+	}
+}
+
+// acquire return the cached statement of query, which is prepared if absent,
+// and must be released after use.
+func (c *StmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if cs := c.lookup(query); cs != nil {
+		c.mu.Unlock()
+		atomic.AddUint64(&c.hits, 1)
+		return cs, nil
+	}
+	c.mu.Unlock()
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cs := c.lookup(query); cs != nil {
+		// prepared by another caller meanwhile
+		_ = stmt.Close()
+		return cs, nil
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.ll.PushFront(cs)
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+	return cs, nil
+}
+
+// lookup return the cached statement of query with a reference, or nil if
+// absent. The c.mu must be held.
+func (c *StmtCache) lookup(query string) *cachedStmt {
+	el, ok := c.items[query]
+	if !ok {
+		return nil
+	}
+	c.ll.MoveToFront(el)
+	cs := el.Value.(*cachedStmt)
+	cs.refs++
+	return cs
+}
+
+// evict removes the element from the cache. The c.mu must be held.
+func (c *StmtCache) evict(el *list.Element) {
+	cs := c.ll.Remove(el).(*cachedStmt)
+	delete(c.items, cs.query)
+	cs.evicted = true
+	atomic.AddUint64(&c.evictions, 1)
+	if cs.refs == 0 {
+		_ = cs.stmt.Close()
+	}
+}
+
+func (c *StmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		// the rows of it are still readable, sql.Stmt is closed after them
+		_ = cs.stmt.Close()
+	}
+}
+
+// queryContext queries by the cached statement, or by the database if the
+// statement can not be prepared, so that the error is the one of query.
+func (c *StmtCache) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	cs, err := c.acquire(ctx, query)
+	if err != nil {
+		return c.db.QueryContext(ctx, query, args...)
+	}
+	defer c.release(cs)
+	return cs.stmt.QueryContext(ctx, args...)
+}
+
+// execContext executes by the cached statement like queryContext.
+func (c *StmtCache) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	cs, err := c.acquire(ctx, query)
+	if err != nil {
+		return c.db.ExecContext(ctx, query, args...)
+	}
+	defer c.release(cs)
+	return cs.stmt.ExecContext(ctx, args...)
+}
+
+// lookupTx return the cached statement of query for the transactions with a
+// reference, or nil if absent. The absent statement is not prepared, which
+// needs another connection than the one of the transaction.
+func (c *StmtCache) lookupTx(query string) *cachedStmt {
+	c.mu.Lock()
+	cs := c.lookup(query)
+	c.mu.Unlock()
+	if cs != nil {
+		atomic.AddUint64(&c.hits, 1)
+	}
+	return cs
+}
+
+// queryTxContext queries within the transaction by the cached statement
+// rebound to it, or by the transaction if it is absent. The rebound statement
+// is closed with the transaction.
+func (c *StmtCache) queryTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (*sql.Rows, error) {
+	cs := c.lookupTx(query)
+	if cs == nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	defer c.release(cs)
+	return tx.StmtContext(ctx, cs.stmt).QueryContext(ctx, args...)
+}
+
+// execTxContext executes within the transaction like queryTxContext.
+func (c *StmtCache) execTxContext(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (sql.Result, error) {
+	cs := c.lookupTx(query)
+	if cs == nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	defer c.release(cs)
+	return tx.StmtContext(ctx, cs.stmt).ExecContext(ctx, args...)
+}
@@ -0,0 +1,108 @@
+package connection
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStmtCache(t *testing.T) {
+	conn := openSqlite(t, Database{StmtCacheSize: 2})
+	mustExec(t, conn, "create table s (id integer)", "insert into s values (1)")
+
+	for i := 0; i < 2; i++ {
+		if _, err := conn.Query("select * from s"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := conn.Query("select * from missing"); err == nil {
+		t.Fatal("query of missing table succeeded")
+	}
+
+	want := StmtCacheStats{Hits: 1, Misses: 3, Evictions: 1, Len: 2}
+	if got := conn.StmtCache("default").Stats(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	if _, err := WithDriver(conn).Table("s").All(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := conn.BeginTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WithDriver(conn).Table("s").WithTx(tx).All(); err != nil {
+		t.Fatal(err)
+	}
+	// the cached statement is rebound to the transaction, and the absent one
+	// is not prepared for it
+	ctx := TxContext(context.Background(), "default")
+	if _, err := conn.QueryWithTxContext(ctx, tx, "select * from s"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.ExecWithTxContext(ctx, tx, "insert into s values (2)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.QueryWithTx(tx, "select * from s"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	want = StmtCacheStats{Hits: 3, Misses: 4, Evictions: 2, Len: 2}
+	if got := conn.StmtCache("default").Stats(); got != want {
+		t.Errorf("stats after transaction = %+v, want %+v", got, want)
+	}
+
+	conn.StmtCache("default").Clear()
+	if got := conn.StmtCache("default").Stats().Len; got != 0 {
+		t.Errorf("len after clear = %d, want 0", got)
+	}
+	if conn.StmtCache("other") != nil {
+		t.Error("cache of unknown connection is not nil")
+	}
+}
+
+func TestTxOptions(t *testing.T) {
+	double := func(v interface{}) (interface{}, error) {
+		return v.(int64) * 2, nil
+	}
+	conn := openSqlite(t, Database{Converters: Converters{Integer: double}, StmtCacheSize: 4})
+	mustExec(t, conn, "create table s (id integer)", "insert into s values (21)")
+
+	tx, err := conn.BeginTx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// the converters of the connection apply to all the methods within tx
+	tests := []struct {
+		name  string
+		query func() ([]map[string]interface{}, error)
+	}{
+		{"builder", func() ([]map[string]interface{}, error) {
+			return WithDriver(conn).Table("s").WithTx(tx).All()
+		}},
+		{"raw", func() ([]map[string]interface{}, error) {
+			return conn.QueryWithTx(tx, "select * from s")
+		}},
+		{"raw with connection", func() ([]map[string]interface{}, error) {
+			return conn.QueryWithTxContext(TxContext(context.Background(), "default"), tx, "select * from s")
+		}},
+		{"raw without connection", func() ([]map[string]interface{}, error) {
+			return conn.QueryWithTxContext(context.Background(), tx, "select * from s")
+		}},
+	}
+	for _, tt := range tests {
+		rows, err := tt.query()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rows[0]["id"] != int64(42) {
+			t.Errorf("%s: id = %v, want 42", tt.name, rows[0]["id"])
+		}
+	}
+}
@@ -113,11 +113,7 @@ func DT(s string) DatabaseType {
 // GetDTAndCheck check the DatabaseType.
 func GetDTAndCheck(s string) DatabaseType {
 	ss := DatabaseType(s)
-	if !Contains(ss, BoolTypeList) &&
-		!Contains(ss, IntTypeList) &&
-		!Contains(ss, FloatTypeList) &&
-		!Contains(ss, UintTypeList) &&
-		!Contains(ss, StringTypeList) {
+	if _, ok := scanKinds[ss]; !ok {
 		panic("wrong type: " + s)
 	}
 	return ss
@@ -143,7 +139,7 @@ var (
 		Tinyint,
 		Mediumint,
 		Smallint,
-		Numeric, Smallserial, Serial, Bigserial, Money,
+		Smallserial, Serial, Bigserial, Money,
 		Integer,
 		Bigint}
 
@@ -151,7 +147,7 @@ var (
 	FloatTypeList = []DatabaseType{Float, Double, Real, Doubleprecision}
 
 	// UintTypeList is a DatabaseType list of uint.
-	UintTypeList = []DatabaseType{Decimal, Bit}
+	UintTypeList = []DatabaseType{Decimal, Numeric, Bit}
 )
 
 // Contains check the given DatabaseType is in the list or not.
@@ -183,13 +179,13 @@ func (v Value) String() string {
 
 // GetValueFromDatabaseType return Value of given DatabaseType and interface.
 func GetValueFromDatabaseType(typ DatabaseType, value interface{}) Value {
-	switch {
-	case Contains(typ, StringTypeList):
+	switch scanKindOf(string(typ)) {
+	case scanString:
 		if v, ok := value.(string); ok {
 			return Value(v)
 		}
 		return ""
-	case Contains(typ, BoolTypeList):
+	case scanBool:
 		if v, ok := value.(bool); ok {
 			if v {
 				return "true"
@@ -197,17 +193,17 @@ func GetValueFromDatabaseType(typ DatabaseType, value interface{}) Value {
 			return "false"
 		}
 		return "false"
-	case Contains(typ, IntTypeList):
+	case scanInt:
 		if v, ok := value.(int64); ok {
 			return Value(fmt.Sprintf("%d", v))
 		}
 		return "0"
-	case Contains(typ, FloatTypeList):
+	case scanFloat:
 		if v, ok := value.(float64); ok {
 			return Value(fmt.Sprintf("%f", v))
 		}
 		return "0"
-	case Contains(typ, UintTypeList):
+	case scanBytes:
 		if v, ok := value.([]uint8); ok {
 			return Value(string(v))
 		}